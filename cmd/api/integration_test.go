@@ -58,6 +58,21 @@ func testAggregation(t *testing.T, data string, body string, expected string) {
 	})
 }
 
+func testFacets(t *testing.T, data string, body string, expected string) {
+	testutils.ParallelTestWithDb(t, data, func(t *testing.T, db *gorm.DB) {
+		repo := repository.New(db)
+		router := gin.Default()
+		router.POST("/occurrences/:seq_id/facets", server.OccurrencesFacetsHandler(repo))
+
+		req, _ := http.NewRequest("POST", "/occurrences/1/facets", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, expected, w.Body.String())
+	})
+}
+
 func TestIntegrationOccurrencesList(t *testing.T) {
 	testList(t, "simple", "{}", `[{"locus_id":1000}]`)
 }
@@ -78,6 +93,23 @@ func TestIntegrationOccurrencesListSqon(t *testing.T) {
 
 }
 
+func TestIntegrationOccurrencesListQualityFilter(t *testing.T) {
+	body := `{
+			"selected_fields":[
+				"locus_id","dp","gq","calls","symbol","consequence","af","canonical","gnomad_v3_af"
+			],
+			"sqon":{
+				"op":"and",
+				"content":[
+					{"op":">=", "field": "gq", "value": 20},
+					{"op":">=", "field": "dp", "value": 10}
+				]
+			}
+		}`
+	expected := `[{"locus_id":1000, "dp":30, "gq":99, "calls":[0,1], "symbol":"BRCA1", "consequence":["missense_variant","splice_region_variant"], "af":0, "canonical":false, "gnomad_v3_af":null}]`
+	testList(t, "qc", body, expected)
+}
+
 func TestIntegrationOccurrencesCount(t *testing.T) {
 	testCount(t, "simple", "{}", 1)
 
@@ -117,13 +149,54 @@ func TestIntegrationAggregation(t *testing.T) {
 			},
 			"size": 10
 		}`
-	expected := `[{"key": "HET", "count": 2}, {"key": "HOM", "count": 1}]`
+	expected := `{"buckets": [{"key": "HET", "count": 2}, {"key": "HOM", "count": 1}], "other_count": 0}`
 	testAggregation(t, "aggregation", body, expected)
 }
 
+func TestIntegrationAggregationExcludeSelf(t *testing.T) {
+	body := `{
+			"field": "zygosity",
+			"sqon": {
+				"op": "and",
+				"content": [
+					{"op": "in", "field": "filter", "value": "PASS"},
+					{"op": "in", "field": "zygosity", "value": "HOM"}
+				]
+			},
+			"exclude_self": true
+		}`
+	expected := `{"buckets": [{"key": "HET", "count": 2}, {"key": "HOM", "count": 1}], "other_count": 0}`
+	testAggregation(t, "aggregation", body, expected)
+}
+
+func TestIntegrationAggregationSizeAndMissing(t *testing.T) {
+	body := `{
+			"field": "symbol",
+			"size": 1,
+			"order": "key_desc",
+			"missing": true
+		}`
+	expected := `{"buckets": [{"key": "TTN", "count": 1}], "other_count": 2, "missing": 0}`
+	testAggregation(t, "qc", body, expected)
+}
+
 func TestMain(m *testing.M) {
 	testutils.SetupContainer()
 	code := m.Run()
 	testutils.StopContainer()
 	os.Exit(code)
 }
+
+func TestIntegrationFacets(t *testing.T) {
+	body := `{
+			"fields": ["zygosity", "consequence"],
+			"size": 2,
+			"order": "key_asc",
+			"missing": true
+		}`
+	expected := `{
+			"zygosity": {"buckets": [{"key": "HET", "count": 2}, {"key": "HOM", "count": 1}], "other_count": 0, "missing": 0},
+			"consequence": {"buckets": [{"key": "missense_variant", "count": 1}, {"key": "splice_region_variant", "count": 1}], "other_count": 2, "missing": 0}
+		}`
+	testFacets(t, "qc", body, expected)
+}
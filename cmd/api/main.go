@@ -6,7 +6,11 @@ import (
 	"go-poc/internal/database"
 	"go-poc/internal/repository"
 	"go-poc/internal/server"
+	"go-poc/internal/types"
 	"log"
+	"os"
+	"strconv"
+	"time"
 )
 
 func main() {
@@ -17,16 +21,87 @@ func main() {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 
-	// Create repository
-	repo := repository.New(db)
+	// Load gene panels usable in filters
+	if dir := os.Getenv("GENE_PANELS_DIR"); dir != "" {
+		panels, err := types.LoadGenePanels(dir)
+		if err != nil {
+			log.Fatalf("Failed to load gene panels: %v", err)
+		}
+		types.RegisterGenePanels(panels)
+	}
+
+	// Create repository, caching the partitions of all the sequencing experiments
+	partCacheTTL := repository.DefaultPartCacheTTL
+	if ttl := os.Getenv("PART_CACHE_TTL"); ttl != "" {
+		if partCacheTTL, err = time.ParseDuration(ttl); err != nil {
+			log.Fatalf("Invalid PART_CACHE_TTL: %v", err)
+		}
+	}
+	repo := repository.NewWithPartCache(db, repository.NewPartCache(partCacheTTL, repository.DefaultPartCacheNegativeTTL))
+	if count, err := repo.WarmUpPartCache(); err != nil {
+		log.Printf("Failed to warm up partition cache: %v", err)
+	} else {
+		log.Printf("Partition cache warmed up with %d sequencing experiments", count)
+	}
+
+	// Cache the results of the occurrence queries, disabled when RESULT_CACHE_SIZE is 0
+	resultCacheSize, resultCacheTTL := repository.DefaultResultCacheSize, repository.DefaultResultCacheTTL
+	if size := os.Getenv("RESULT_CACHE_SIZE"); size != "" {
+		if resultCacheSize, err = strconv.Atoi(size); err != nil {
+			log.Fatalf("Invalid RESULT_CACHE_SIZE: %v", err)
+		}
+	}
+	if ttl := os.Getenv("RESULT_CACHE_TTL"); ttl != "" {
+		if resultCacheTTL, err = time.ParseDuration(ttl); err != nil {
+			log.Fatalf("Invalid RESULT_CACHE_TTL: %v", err)
+		}
+	}
+	results := repository.NewResultCache(resultCacheSize, resultCacheTTL)
+	cached := func(handler func(repository.Repository) gin.HandlerFunc) gin.HandlerFunc {
+		if resultCacheSize <= 0 {
+			return handler(repo)
+		}
+		return server.WithResultCache(repo, results, handler)
+	}
 
 	r := gin.Default()
 	r.Use(gzip.Gzip(gzip.DefaultCompression))
 
 	r.GET("/status", server.StatusHandler(repo))
-	r.POST("/occurrences/:seq_id/count", server.OccurrencesCountHandler(repo))
-	r.POST("/occurrences/:seq_id/list", server.OccurrencesListHandler(repo))
-	r.POST("/occurrences/:seq_id/aggregate", server.OccurrencesAggregateHandler(repo))
+	r.POST("/occurrences/:seq_id/count", cached(server.OccurrencesCountHandler))
+	r.POST("/occurrences/:seq_id/list", cached(server.OccurrencesListHandler))
+	r.POST("/occurrences/:seq_id/aggregate", cached(server.OccurrencesAggregateHandler))
+	r.POST("/occurrences/:seq_id/facets", cached(server.OccurrencesFacetsHandler))
+	r.POST("/occurrences/:seq_id/histogram", cached(server.OccurrencesHistogramHandler))
+	r.POST("/occurrences/:seq_id/stats", cached(server.OccurrencesStatsHandler))
+	r.POST("/occurrences/:seq_id/family/list", server.FamilyOccurrencesListHandler(repo))
+	r.POST("/occurrences/:seq_id/family/aggregate", server.FamilyOccurrencesAggregateHandler(repo))
+	r.POST("/occurrences/:seq_id/compound-het", server.CompoundHetHandler(repo))
+	r.POST("/cohort/count", server.CohortCountHandler(repo))
+	r.POST("/cohort/carriers", server.CohortCarriersHandler(repo))
+	r.POST("/sequencing/count", server.SequencingExperimentsCountHandler(repo))
+	r.POST("/sequencing/list", server.SequencingExperimentsListHandler(repo))
+	r.GET("/sequencing/search", server.SequencingExperimentsSearchHandler(repo))
+	r.GET("/sequencing/:seq_id", server.SequencingExperimentHandler(repo))
+	r.GET("/sequencing/:seq_id/family", server.FamilyHandler(repo))
+	r.POST("/variants/count", server.VariantsCountHandler(repo))
+	r.POST("/variants/list", server.VariantsListHandler(repo))
+	r.POST("/variants/aggregate", server.VariantsAggregateHandler(repo))
+	r.GET("/variants/:locus_id", server.VariantHandler(repo))
+	r.GET("/variants/:locus_id/occurrences", server.VariantOccurrencesHandler(repo))
+
+	// Admin endpoints are only served when a token is configured
+	if token := os.Getenv("ADMIN_TOKEN"); token != "" {
+		admin := r.Group("/admin", server.AdminAuth(token))
+		admin.GET("/cache/parts", server.PartCacheStatsHandler(repo))
+		admin.DELETE("/cache/parts", server.PartCacheInvalidateHandler(repo))
+		admin.DELETE("/cache/parts/:seq_id", server.PartCacheInvalidateHandler(repo))
+		admin.GET("/cache/results", server.ResultCacheStatsHandler(results))
+		admin.DELETE("/cache/results", server.ResultCacheInvalidateHandler(repo, results))
+		admin.DELETE("/cache/results/:seq_id", server.ResultCacheInvalidateHandler(repo, results))
+	} else {
+		log.Printf("ADMIN_TOKEN is not set, admin endpoints are disabled")
+	}
 
 	r.Run(":8080")
 }
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/Goldziher/go-utils/sliceutils"
+	"go-poc/internal/types"
+	"gorm.io/gorm"
+	"slices"
+	"strings"
+)
+
+// MaxCohortSize is the maximum number of sequencing experiments queried at once
+const MaxCohortSize = 1000
+
+// GetCohort returns the sequencing experiments of a cohort, or ErrNotFound if the cohort does not exist
+func (r *MySQLRepository) GetCohort(cohortId string) ([]int, error) {
+	var seqIds []int
+	err := r.db.Table("cohort").
+		Where("cohort_id = ?", cohortId).
+		Order("seq_id").
+		Pluck("seq_id", &seqIds).Error
+	if err != nil {
+		return nil, fmt.Errorf("error fetching cohort: %w", err)
+	}
+	if len(seqIds) == 0 {
+		return nil, fmt.Errorf("cohort %s: %w", cohortId, ErrNotFound)
+	}
+	return seqIds, nil
+}
+
+// CountCohortOccurrences counts the occurrences matching the user query of each sequencing experiment, in the given order
+func (r *MySQLRepository) CountCohortOccurrences(seqIds []int, userQuery *types.Query) ([]types.SampleCount, error) {
+	parts, err := r.getParts(seqIds)
+	if err != nil {
+		return nil, err
+	}
+	var counts []types.SampleCount
+	err = buildCohortQuery(r.db, parts, userQuery).
+		Select("o.seq_id as seq_id, count(1) as count").
+		Group("o.seq_id").
+		Find(&counts).Error
+	if err != nil {
+		return nil, fmt.Errorf("error counting cohort occurrences: %w", err)
+	}
+	bySeqId := map[int]int64{}
+	for _, count := range counts {
+		bySeqId[count.SeqId] = count.Count
+	}
+	return sliceutils.Map(sliceutils.Unique(seqIds), func(seqId int, index int, slice []int) types.SampleCount {
+		return types.SampleCount{SeqId: seqId, Count: bySeqId[seqId]}
+	}), nil
+}
+
+// GetCohortCarriers returns the loci of the occurrences matching the user query with their number of carriers,
+// ordered by decreasing number of carriers
+func (r *MySQLRepository) GetCohortCarriers(seqIds []int, userQuery *types.Query) ([]types.LocusCarriers, error) {
+	parts, err := r.getParts(seqIds)
+	if err != nil {
+		return nil, err
+	}
+	tx := buildCohortQuery(r.db, parts, userQuery).
+		Select("o.locus_id as locus_id, count(distinct o.seq_id) as carriers, " +
+			"sum(case when o.zygosity = 'HET' then 1 else 0 end) as het, " +
+			"sum(case when o.zygosity = 'HOM' then 1 else 0 end) as hom").
+		Group("o.locus_id").
+		Order("carriers desc, locus_id asc")
+	addLimitAndSort(tx, userQuery)
+	carriers := []types.LocusCarriers{}
+	if err = tx.Find(&carriers).Error; err != nil {
+		return nil, fmt.Errorf("error fetching cohort carriers: %w", err)
+	}
+	return carriers, nil
+}
+
+// getParts returns the sequencing experiments grouped by partition, or ErrNotFound if one of them does not exist.
+// Only the sequencing experiments missing from the partition cache are fetched.
+func (r *MySQLRepository) getParts(seqIds []int) (map[int][]int, error) {
+	parts := map[int][]int{}
+	var uncached, missing []int
+	for _, seqId := range sliceutils.Unique(seqIds) {
+		part, found, ok := r.parts.get(seqId)
+		switch {
+		case !ok:
+			uncached = append(uncached, seqId)
+		case found:
+			parts[part] = append(parts[part], seqId)
+		default:
+			missing = append(missing, seqId)
+		}
+	}
+	if len(uncached) > 0 {
+		var experiments []struct {
+			SeqId int
+			Part  int
+		}
+		err := r.db.Table("sequencing_experiment").
+			Select("seq_id, part").
+			Where("seq_id IN ?", uncached).
+			Find(&experiments).Error
+		if err != nil {
+			return nil, fmt.Errorf("error fetching partitions: %w", err)
+		}
+		found := map[int]bool{}
+		for _, experiment := range experiments {
+			parts[experiment.Part] = append(parts[experiment.Part], experiment.SeqId)
+			found[experiment.SeqId] = true
+			r.parts.set(experiment.SeqId, experiment.Part, true)
+		}
+		for _, seqId := range uncached {
+			if !found[seqId] {
+				missing = append(missing, seqId)
+				r.parts.set(seqId, 0, false)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("sequencing experiments %v: %w", missing, ErrNotFound)
+	}
+	return parts, nil
+}
+
+// buildCohortQuery returns the query on the occurrences of sequencing experiments grouped by partition matching the filters
+// of the user query, so that each partition is scanned once
+func buildCohortQuery(db *gorm.DB, parts map[int][]int, userQuery *types.Query) *gorm.DB {
+	keys := make([]int, 0, len(parts))
+	for part := range parts {
+		keys = append(keys, part)
+	}
+	slices.Sort(keys)
+	conditions := make([]string, len(keys))
+	var params []interface{}
+	for i, part := range keys {
+		conditions[i] = "(o.part = ? and o.seq_id IN ?)"
+		params = append(params, part, parts[part])
+	}
+	tx := db.Table("occurrences o").
+		Where("o.part IN ? and o.has_alt", keys).
+		Where(fmt.Sprintf("(%s)", strings.Join(conditions, " OR ")), params...)
+	if userQuery != nil {
+		if joinsVariants(userQuery) {
+			tx = tx.Joins("JOIN variants v ON v.locus_id=o.locus_id")
+		}
+		if userQuery.Filters != nil {
+			filters, params := userQuery.Filters.ToSQL()
+			tx = tx.Where(filters, params...)
+		}
+	}
+	return tx
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-poc/internal/types"
+	"go-poc/test/testutils"
+	"gorm.io/gorm"
+	"testing"
+)
+
+var highImpactQuery = types.Query{
+	Filters: &types.ComparisonNode{
+		Operator: "in",
+		Value:    "HIGH",
+		Field:    types.VepImpactField,
+	},
+	FilteredFields: []types.Field{types.VepImpactField},
+}
+
+func TestGetCohort(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "cohort", func(t *testing.T, db *gorm.DB) {
+		repo := New(db)
+		seqIds, err := repo.GetCohort("C1")
+		if assert.NoError(t, err) {
+			assert.Equal(t, []int{1, 2, 3}, seqIds)
+		}
+
+		_, err = repo.GetCohort("C2")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestCountCohortOccurrences(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "cohort", func(t *testing.T, db *gorm.DB) {
+		repo := New(db)
+		query := highImpactQuery
+		counts, err := repo.CountCohortOccurrences([]int{3, 1, 2}, &query)
+		if assert.NoError(t, err) {
+			assert.Equal(t, []types.SampleCount{
+				{SeqId: 3, Count: 1},
+				{SeqId: 1, Count: 2},
+				{SeqId: 2, Count: 1},
+			}, counts)
+		}
+	})
+}
+
+func TestCountCohortOccurrencesUnknownExperiment(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "cohort", func(t *testing.T, db *gorm.DB) {
+		repo := New(db)
+		_, err := repo.CountCohortOccurrences([]int{1, 42}, &types.Query{})
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestGetCohortCarriers(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "cohort", func(t *testing.T, db *gorm.DB) {
+		repo := New(db)
+		query := highImpactQuery
+		query.Pagination = &types.Pagination{Limit: 10}
+		carriers, err := repo.GetCohortCarriers([]int{1, 2, 3}, &query)
+		if assert.NoError(t, err) {
+			assert.Equal(t, []types.LocusCarriers{
+				{LocusId: 1000, Carriers: 3, Het: 2, Hom: 1},
+				{LocusId: 1001, Carriers: 1, Het: 0, Hom: 1},
+			}, carriers)
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"go-poc/internal/types"
+)
+
+// MaxCompoundHetOccurrences is the maximum number of heterozygous occurrences grouped by gene in a compound heterozygous analysis
+const MaxCompoundHetOccurrences = 10000
+
+// GetCompoundHets returns a page of the genes having pairs of heterozygous occurrences matching the user query, along with
+// the total number of such genes. Pairs are restricted to variants inherited from different parents when the sequencing
+// experiment is a proband with both parents sequenced.
+func (r *MySQLRepository) GetCompoundHets(seqId int, userQuery *types.Query) ([]types.CompoundHetGene, int64, error) {
+	var parents *familyParts
+	family, err := r.GetFamily(seqId)
+	if err == nil {
+		// The origin of the variants cannot be deduced from a single parent
+		if family.MotherSeqId != nil && family.FatherSeqId != nil {
+			if parents, err = r.getParentParts(family); err != nil {
+				return nil, 0, err
+			}
+		}
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, 0, err
+	}
+
+	tx, _, err := prepareQuery(seqId, userQuery, r)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error during query preparation %w", err)
+	}
+	if !joinsVariants(userQuery) {
+		tx = tx.Joins("JOIN variants v ON v.locus_id=o.locus_id")
+	}
+	joinParents(tx, parents)
+
+	// The fields used for grouping and phasing are scanned after the selected ones
+	phasingFields := []types.Field{types.LocusIdField, types.SymbolField}
+	if parents != nil {
+		phasingFields = append(phasingFields, types.MotherZygosityField, types.FatherZygosityField)
+	}
+	scannedFields := userQuery.SelectedFields[:len(userQuery.SelectedFields):len(userQuery.SelectedFields)]
+	for i, field := range phasingFields {
+		field.Alias = fmt.Sprintf("phasing_%d", i)
+		scannedFields = append(scannedFields, field)
+	}
+	tx = tx.Select(selectColumns(scannedFields)).
+		Where("o.zygosity = ? AND v.symbol IS NOT NULL", "HET").
+		Order("v.symbol, o.locus_id").
+		Limit(MaxCompoundHetOccurrences + 1)
+	rows, err := scanRows(tx, scannedFields)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error fetching heterozygous occurrences: %w", err)
+	}
+	if len(rows) > MaxCompoundHetOccurrences {
+		return nil, 0, fmt.Errorf("more than %d heterozygous occurrences: %w", MaxCompoundHetOccurrences, ErrTooManyOccurrences)
+	}
+
+	calls := make([]types.HetCall, len(rows))
+	for i, row := range rows {
+		splitKeyset(&row, len(userQuery.SelectedFields))
+		locusId, ok := row.Keyset[0].(int64)
+		if !ok {
+			return nil, 0, fmt.Errorf("unexpected locus_id %v of type %T", row.Keyset[0], row.Keyset[0])
+		}
+		symbol, ok := row.Keyset[1].(string)
+		if !ok {
+			return nil, 0, fmt.Errorf("unexpected symbol %v of type %T", row.Keyset[1], row.Keyset[1])
+		}
+		calls[i] = types.HetCall{LocusId: locusId, Symbol: symbol, Row: row}
+		if parents != nil {
+			calls[i].MotherZygosity, calls[i].FatherZygosity = row.Keyset[2], row.Keyset[3]
+		}
+		calls[i].Row.Keyset = nil
+	}
+	genes := types.FindCompoundHets(calls, parents != nil)
+	return paginateGenes(genes, userQuery.Pagination), int64(len(genes)), nil
+}
+
+// paginateGenes returns the page of genes, limited to MinLimit genes without pagination
+func paginateGenes(genes []types.CompoundHetGene, pagination *types.Pagination) []types.CompoundHetGene {
+	limit, offset := MinLimit, 0
+	if pagination != nil {
+		limit, offset = min(pagination.Limit, MaxLimit), pagination.Offset
+	}
+	if offset >= len(genes) {
+		return []types.CompoundHetGene{}
+	}
+	return genes[offset:min(offset+limit, len(genes))]
+}
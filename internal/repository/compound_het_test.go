@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-poc/internal/types"
+	"go-poc/test/testutils"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestGetCompoundHetsPhased(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "compound_het", func(t *testing.T, db *gorm.DB) {
+		repo := New(db)
+		query := types.Query{
+			SelectedFields: []types.Field{types.LocusIdField, types.PfField},
+			Pagination:     &types.Pagination{Limit: 10},
+		}
+		genes, total, err := repo.GetCompoundHets(1, &query)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, total)
+		if assert.Len(t, genes, 1) {
+			assert.Equal(t, "BRCA1", genes[0].Symbol)
+			assert.True(t, genes[0].Phased)
+			assert.Equal(t, [][2]int64{{1000, 1001}, {1001, 1002}}, genes[0].Pairs)
+			assert.Equal(t, 2, genes[0].PairCount)
+			if assert.Len(t, genes[0].Variants, 3) {
+				assert.Equal(t, []string{"locus_id", "pf", "origin"}, genes[0].Variants[0].Columns)
+				assert.Equal(t, []interface{}{int64(1001), 0.2, types.PaternalOrigin}, genes[0].Variants[1].Values)
+			}
+		}
+	})
+}
+
+func TestGetCompoundHetsWithoutFamily(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "compound_het", func(t *testing.T, db *gorm.DB) {
+		repo := New(db)
+		query := types.Query{
+			SelectedFields: []types.Field{types.LocusIdField},
+			Pagination:     &types.Pagination{Limit: 10},
+		}
+		genes, total, err := repo.GetCompoundHets(4, &query)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, total)
+		if assert.Len(t, genes, 1) {
+			assert.Equal(t, "TTN", genes[0].Symbol)
+			assert.False(t, genes[0].Phased)
+			assert.Equal(t, [][2]int64{{1003, 1004}}, genes[0].Pairs)
+		}
+	})
+}
+
+func TestGetCompoundHetsWithSingleParent(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "compound_het_single_parent", func(t *testing.T, db *gorm.DB) {
+		repo := New(db)
+		query := types.Query{
+			SelectedFields: []types.Field{types.LocusIdField},
+			Pagination:     &types.Pagination{Limit: 10},
+		}
+		genes, total, err := repo.GetCompoundHets(1, &query)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, total)
+		if assert.Len(t, genes, 2) {
+			assert.Equal(t, "BRCA1", genes[0].Symbol)
+			assert.False(t, genes[0].Phased)
+			assert.Equal(t, [][2]int64{{1000, 1001}, {1000, 1002}, {1001, 1002}}, genes[0].Pairs)
+			assert.Equal(t, 3, genes[0].PairCount)
+		}
+	})
+}
+
+func TestGetCompoundHetsFilterAndPagination(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "compound_het", func(t *testing.T, db *gorm.DB) {
+		repo := New(db)
+		query := types.Query{
+			Filters: &types.ComparisonNode{
+				Operator: "<",
+				Value:    0.3,
+				Field:    types.PfField,
+			},
+			FilteredFields: []types.Field{types.PfField},
+			SelectedFields: []types.Field{types.LocusIdField},
+			Pagination:     &types.Pagination{Limit: 10, Offset: 1},
+		}
+		genes, total, err := repo.GetCompoundHets(1, &query)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, total)
+		assert.Empty(t, genes)
+	})
+}
+
+func TestGetCompoundHetsUnknownExperiment(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "compound_het", func(t *testing.T, db *gorm.DB) {
+		repo := New(db)
+		query := types.Query{SelectedFields: []types.Field{types.LocusIdField}}
+		_, _, err := repo.GetCompoundHets(42, &query)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
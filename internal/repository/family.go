@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"fmt"
+	"go-poc/internal/types"
+	"gorm.io/gorm"
+)
+
+// familyParts gives the sequencing experiments of the parents of a proband along with their partitions
+type familyParts struct {
+	types.Family
+	MotherPart int
+	FatherPart int
+}
+
+func (r *MySQLRepository) GetFamilyOccurrences(seqId int, userQuery *types.Query) ([]Row, error) {
+	parents, err := r.getFamilyParts(seqId)
+	if err != nil {
+		return nil, err
+	}
+	return r.getOccurrences(seqId, userQuery, parents)
+}
+
+func (r *MySQLRepository) CountFamilyOccurrences(seqId int, userQuery *types.Query) (int64, error) {
+	parents, err := r.getFamilyParts(seqId)
+	if err != nil {
+		return 0, err
+	}
+	tx, _, err := prepareQuery(seqId, userQuery, r)
+	if err != nil {
+		return 0, fmt.Errorf("error during query preparation %w", err)
+	}
+	joinParents(tx, parents)
+	var count int64
+	if err = tx.Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("error counting family occurrences: %w", err)
+	}
+	return count, nil
+}
+
+func (r *MySQLRepository) AggregateFamilyOccurrences(seqId int, userQuery *types.Query, options types.TermsOptions) (TermsAggregation, error) {
+	parents, err := r.getFamilyParts(seqId)
+	if err != nil {
+		return TermsAggregation{}, err
+	}
+	part, err := r.GetPart(seqId)
+	if err != nil {
+		return TermsAggregation{}, fmt.Errorf("error during partition fetch %w", err)
+	}
+	return aggregateTerms(func() *gorm.DB {
+		tx := buildQuery(r.db, seqId, part, userQuery)
+		joinParents(tx, parents)
+		return tx
+	}, userQuery.SelectedFields[0], options)
+}
+
+// GetFamily returns the family of a proband, or ErrNotFound if the sequencing experiment is not a proband
+func (r *MySQLRepository) GetFamily(seqId int) (*types.Family, error) {
+	var families []types.Family
+	err := r.db.Table("family").
+		Select("proband_seq_id, mother_seq_id, father_seq_id").
+		Where("proband_seq_id = ?", seqId).
+		Find(&families).Error
+	if err != nil {
+		return nil, fmt.Errorf("error fetching family: %w", err)
+	}
+	if len(families) == 0 {
+		return nil, fmt.Errorf("family of sequencing experiment %d: %w", seqId, ErrNotFound)
+	}
+	return &families[0], nil
+}
+
+// getFamilyParts returns the family of a proband along with the partitions of the parents
+func (r *MySQLRepository) getFamilyParts(seqId int) (*familyParts, error) {
+	family, err := r.GetFamily(seqId)
+	if err != nil {
+		return nil, err
+	}
+	return r.getParentParts(family)
+}
+
+// getParentParts returns the partitions of the parents of a family
+func (r *MySQLRepository) getParentParts(family *types.Family) (*familyParts, error) {
+	var err error
+	parents := familyParts{Family: *family}
+	if family.MotherSeqId != nil {
+		if parents.MotherPart, err = r.GetPart(*family.MotherSeqId); err != nil {
+			return nil, fmt.Errorf("error during mother partition fetch %w", err)
+		}
+	}
+	if family.FatherSeqId != nil {
+		if parents.FatherPart, err = r.GetPart(*family.FatherSeqId); err != nil {
+			return nil, fmt.Errorf("error during father partition fetch %w", err)
+		}
+	}
+	return &parents, nil
+}
+
+// joinParents adds the occurrences of the parents at the locus of each occurrence of the proband, if a family is given.
+// The parent fields are NULL when a parent is not sequenced or has no call at the locus.
+func joinParents(tx *gorm.DB, parents *familyParts) {
+	if parents == nil {
+		return
+	}
+	tx.Joins("LEFT JOIN occurrences mo ON mo.part = ? AND mo.seq_id = ? AND mo.locus_id = o.locus_id", parents.MotherPart, parents.MotherSeqId).
+		Joins("LEFT JOIN occurrences fa ON fa.part = ? AND fa.seq_id = ? AND fa.locus_id = o.locus_id", parents.FatherPart, parents.FatherSeqId)
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-poc/internal/types"
+	"go-poc/test/testutils"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestGetFamily(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "family", func(t *testing.T, db *gorm.DB) {
+		repo := New(db)
+		family, err := repo.GetFamily(1)
+		if assert.NoError(t, err) {
+			assert.Equal(t, 1, family.ProbandSeqId)
+			assert.Equal(t, 2, *family.MotherSeqId)
+			assert.Equal(t, 3, *family.FatherSeqId)
+		}
+
+		_, err = repo.GetFamily(2)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestGetFamilyOccurrences(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "family", func(t *testing.T, db *gorm.DB) {
+		repo := New(db)
+		query := types.Query{
+			SelectedFields: []types.Field{
+				types.LocusIdField, types.ZygosityField,
+				types.MotherZygosityField, types.MotherGqField,
+				types.FatherZygosityField, types.FatherAdRatioField,
+			},
+		}
+		occurrences, err := repo.GetFamilyOccurrences(1, &query)
+		assert.NoError(t, err)
+		if assert.Len(t, occurrences, 3) {
+			byLocus := map[int64]Row{}
+			for _, o := range occurrences {
+				byLocus[o.Get("locus_id").(int64)] = o
+			}
+			assert.Equal(t, "WT", byLocus[1000].Get("mother_zygosity"))
+			assert.EqualValues(t, 50, byLocus[1000].Get("mother_gq"))
+			assert.Equal(t, "WT", byLocus[1000].Get("father_zygosity"))
+			assert.Equal(t, "HET", byLocus[1001].Get("mother_zygosity"))
+			assert.Nil(t, byLocus[1001].Get("father_zygosity"))
+			assert.Nil(t, byLocus[1002].Get("mother_zygosity"))
+			assert.Equal(t, 0.5, byLocus[1002].Get("father_ad_ratio"))
+		}
+	})
+}
+
+func TestGetFamilyOccurrencesParentFilter(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "family", func(t *testing.T, db *gorm.DB) {
+		repo := New(db)
+		query := types.Query{
+			Filters: &types.ComparisonNode{
+				Operator: "in",
+				Value:    "WT",
+				Field:    types.MotherZygosityField,
+			},
+			FilteredFields: []types.Field{types.MotherZygosityField},
+			SelectedFields: []types.Field{types.LocusIdField, types.PfField},
+		}
+		occurrences, err := repo.GetFamilyOccurrences(1, &query)
+		assert.NoError(t, err)
+		if assert.Len(t, occurrences, 1) {
+			assert.EqualValues(t, 1000, occurrences[0].Get("locus_id"))
+			assert.Equal(t, 0.1, occurrences[0].Get("pf"))
+		}
+
+		count, err := repo.CountFamilyOccurrences(1, &query)
+		if assert.NoError(t, err) {
+			assert.EqualValues(t, 1, count)
+		}
+	})
+}
+
+func TestGetFamilyOccurrencesInheritance(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "inheritance", func(t *testing.T, db *gorm.DB) {
+		repo := New(db)
+		inheritance, err := types.InheritanceField(types.InheritanceThresholds{})
+		assert.NoError(t, err)
+		query := types.Query{
+			SelectedFields: []types.Field{types.LocusIdField, inheritance},
+		}
+		occurrences, err := repo.GetFamilyOccurrences(1, &query)
+		assert.NoError(t, err)
+		if assert.Len(t, occurrences, 5) {
+			byLocus := map[int64]interface{}{}
+			for _, o := range occurrences {
+				byLocus[o.Get("locus_id").(int64)] = o.Get("inheritance")
+			}
+			assert.Equal(t, types.DeNovo, byLocus[1000])
+			assert.Equal(t, types.XLinkedHemizygous, byLocus[1001])
+			assert.Equal(t, types.AutosomalRecessive, byLocus[1002])
+			assert.Nil(t, byLocus[1003])
+			assert.Nil(t, byLocus[1004])
+		}
+	})
+}
+
+func TestGetFamilyOccurrencesInheritanceThresholds(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "inheritance", func(t *testing.T, db *gorm.DB) {
+		repo := New(db)
+		minGq := 45
+		inheritance, err := types.InheritanceField(types.InheritanceThresholds{MinGq: &minGq})
+		assert.NoError(t, err)
+		query := types.Query{
+			Filters: &types.ComparisonNode{
+				Operator: "in",
+				Value:    types.DeNovo,
+				Field:    inheritance,
+			},
+			FilteredFields: []types.Field{inheritance},
+			SelectedFields: []types.Field{types.LocusIdField},
+		}
+		count, err := repo.CountFamilyOccurrences(1, &query)
+		if assert.NoError(t, err) {
+			assert.EqualValues(t, 0, count)
+		}
+	})
+}
+
+func TestAggregateFamilyOccurrencesInheritance(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "inheritance", func(t *testing.T, db *gorm.DB) {
+		repo := New(db)
+		inheritance, err := types.InheritanceField(types.InheritanceThresholds{})
+		assert.NoError(t, err)
+		query := types.Query{SelectedFields: []types.Field{inheritance}}
+		aggregation, err := repo.AggregateFamilyOccurrences(1, &query, types.TermsOptions{Order: types.OrderByKeyAsc, Missing: true})
+		if assert.NoError(t, err) {
+			assert.Equal(t, []Aggregation{
+				{Bucket: types.AutosomalRecessive, Count: 1},
+				{Bucket: types.DeNovo, Count: 1},
+				{Bucket: types.XLinkedHemizygous, Count: 1},
+			}, aggregation.Buckets)
+			assert.EqualValues(t, 2, *aggregation.Missing)
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultPartCacheTTL         = time.Hour
+	DefaultPartCacheNegativeTTL = time.Minute // Unknown sequencing experiments may be loaded at any time
+	MaxPartCacheNegativeEntries = 10000       // Bounds the memory used by requests on unknown sequencing experiments
+)
+
+// PartCacheStats gives the usage of the partition cache since the start of the application
+type PartCacheStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+	Size   int   `json:"size"`
+}
+
+// PartCache caches the partitions of the sequencing experiments, remembering unknown sequencing experiments as well
+type PartCache struct {
+	mu          sync.RWMutex
+	entries     map[int]partEntry
+	negatives   int // Number of entries of unknown sequencing experiments
+	ttl         time.Duration
+	negativeTTL time.Duration
+	hits        atomic.Int64
+	misses      atomic.Int64
+	now         func() time.Time
+}
+
+type partEntry struct {
+	part      int
+	found     bool
+	expiresAt time.Time
+}
+
+func NewPartCache(ttl time.Duration, negativeTTL time.Duration) *PartCache {
+	return &PartCache{entries: map[int]partEntry{}, ttl: ttl, negativeTTL: negativeTTL, now: time.Now}
+}
+
+// get returns the cached partition of a sequencing experiment and whether it exists, ok is false when not cached or expired.
+// Expired entries are removed.
+func (c *PartCache) get(seqId int) (part int, found bool, ok bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[seqId]
+	c.mu.RUnlock()
+	if ok && !c.now().Before(entry.expiresAt) {
+		c.mu.Lock()
+		// The entry may have been replaced since it was read
+		if entry, ok = c.entries[seqId]; ok && !c.now().Before(entry.expiresAt) {
+			c.remove(seqId)
+		}
+		c.mu.Unlock()
+		ok = false
+	}
+	if !ok {
+		c.misses.Add(1)
+		return 0, false, false
+	}
+	c.hits.Add(1)
+	return entry.part, entry.found, true
+}
+
+// set caches the partition of a sequencing experiment, or the fact that it does not exist when found is false.
+// Unknown sequencing experiments are not cached when there are already MaxPartCacheNegativeEntries of them.
+func (c *PartCache) set(seqId int, part int, found bool) {
+	ttl := c.ttl
+	if !found {
+		ttl = c.negativeTTL
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(seqId)
+	if !found {
+		if c.negatives >= MaxPartCacheNegativeEntries {
+			c.removeExpired()
+		}
+		if c.negatives >= MaxPartCacheNegativeEntries {
+			return
+		}
+		c.negatives++
+	}
+	c.entries[seqId] = partEntry{part: part, found: found, expiresAt: c.now().Add(ttl)}
+}
+
+// remove removes the entry of a sequencing experiment, the lock being held
+func (c *PartCache) remove(seqId int) {
+	if entry, ok := c.entries[seqId]; ok {
+		if !entry.found {
+			c.negatives--
+		}
+		delete(c.entries, seqId)
+	}
+}
+
+// removeExpired removes all the expired entries, the lock being held
+func (c *PartCache) removeExpired() {
+	now := c.now()
+	for seqId, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			c.remove(seqId)
+		}
+	}
+}
+
+// Invalidate removes the given sequencing experiments from the cache, or all of them when none is given
+func (c *PartCache) Invalidate(seqIds ...int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(seqIds) == 0 {
+		c.entries = map[int]partEntry{}
+		c.negatives = 0
+		return
+	}
+	for _, seqId := range seqIds {
+		c.remove(seqId)
+	}
+}
+
+// Stats returns the usage of the cache, expired entries being removed so that they are not counted
+func (c *PartCache) Stats() PartCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.removeExpired()
+	return PartCacheStats{Hits: c.hits.Load(), Misses: c.misses.Load(), Size: len(c.entries)}
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPartCache(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewPartCache(time.Hour, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	_, _, ok := cache.get(1)
+	assert.False(t, ok)
+
+	cache.set(1, 3, true)
+	cache.set(42, 0, false)
+	part, found, ok := cache.get(1)
+	assert.True(t, ok)
+	assert.True(t, found)
+	assert.Equal(t, 3, part)
+	_, found, ok = cache.get(42)
+	assert.True(t, ok)
+	assert.False(t, found)
+
+	// Unknown sequencing experiments expire first
+	now = now.Add(2 * time.Minute)
+	_, _, ok = cache.get(42)
+	assert.False(t, ok)
+	_, _, ok = cache.get(1)
+	assert.True(t, ok)
+
+	now = now.Add(time.Hour)
+	_, _, ok = cache.get(1)
+	assert.False(t, ok)
+
+	// Expired entries are removed
+	assert.Equal(t, PartCacheStats{Hits: 3, Misses: 3, Size: 0}, cache.Stats())
+}
+
+func TestPartCacheStatsIgnoresExpired(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewPartCache(time.Hour, time.Minute)
+	cache.now = func() time.Time { return now }
+	cache.set(1, 3, true)
+	cache.set(42, 0, false)
+
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, 1, cache.Stats().Size)
+}
+
+func TestPartCacheBoundsNegativeEntries(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewPartCache(time.Hour, time.Minute)
+	cache.now = func() time.Time { return now }
+	for seqId := 0; seqId < MaxPartCacheNegativeEntries+10; seqId++ {
+		cache.set(seqId, 0, false)
+	}
+	cache.set(-1, 1, true)
+	assert.Equal(t, MaxPartCacheNegativeEntries+1, cache.Stats().Size)
+	_, _, ok := cache.get(MaxPartCacheNegativeEntries)
+	assert.False(t, ok)
+
+	// Expired entries make room for new ones
+	now = now.Add(2 * time.Minute)
+	cache.set(MaxPartCacheNegativeEntries, 0, false)
+	_, found, ok := cache.get(MaxPartCacheNegativeEntries)
+	assert.True(t, ok)
+	assert.False(t, found)
+	assert.Equal(t, 2, cache.Stats().Size)
+}
+
+func TestPartCacheInvalidate(t *testing.T) {
+	t.Parallel()
+	cache := NewPartCache(time.Hour, time.Minute)
+	cache.set(1, 1, true)
+	cache.set(2, 1, true)
+	cache.set(3, 2, true)
+
+	cache.Invalidate(2)
+	_, _, ok := cache.get(2)
+	assert.False(t, ok)
+	_, _, ok = cache.get(1)
+	assert.True(t, ok)
+
+	cache.Invalidate()
+	assert.Equal(t, 0, cache.Stats().Size)
+}
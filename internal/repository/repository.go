@@ -1,28 +1,71 @@
 package repository
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/Goldziher/go-utils/sliceutils"
 	"go-poc/internal/types"
 	"gorm.io/gorm"
 	"log"
+	"math"
+	"strconv"
+	"strings"
 )
 
-type Occurrence = types.Occurrence
+// ErrNotFound is returned when the sequencing experiment of a request does not exist
+var ErrNotFound = errors.New("not found")
+
+// ErrTooManyOccurrences is returned when an analysis would have to load more occurrences than allowed
+var ErrTooManyOccurrences = errors.New("too many occurrences")
+
+// ErrTooManyBuckets is returned when a histogram interval would give more than MaxHistogramBuckets buckets
+var ErrTooManyBuckets = errors.New("too many buckets")
+
+type Row = types.Row
 type Aggregation = types.Aggregation
+type TermsAggregation = types.TermsAggregation
+type Histogram = types.Histogram
+type FieldStats = types.FieldStats
 type Repository interface {
 	CheckDatabaseConnection() string
-	GetOccurrences(seqId int, userFilter *types.Query) ([]Occurrence, error)
+	GetOccurrences(seqId int, userFilter *types.Query) ([]Row, error)
 	CountOccurrences(seqId int, userQuery *types.Query) (int64, error)
-	AggregateOccurrences(seqId int, userQuery *types.Query) ([]Aggregation, error)
+	AggregateOccurrences(seqId int, userQuery *types.Query, options types.TermsOptions) (TermsAggregation, error)
+	HistogramOccurrences(seqId int, userQuery *types.Query, options types.HistogramOptions) (Histogram, error)
+	StatsOccurrences(seqId int, userQuery *types.Query, percentiles []float64) (map[string]FieldStats, error)
+	GetVariants(userQuery *types.Query) ([]Row, error)
+	CountVariants(userQuery *types.Query) (int64, error)
+	AggregateVariants(userQuery *types.Query, options types.TermsOptions) (TermsAggregation, error)
+	GetVariant(locusId int) (*Row, error)
+	GetVariantOccurrences(locusId int, userQuery *types.Query) ([]Row, error)
+	CountVariantOccurrences(locusId int, userQuery *types.Query) (int64, error)
+	GetSequencingExperiments(userQuery *types.Query) ([]Row, error)
+	CountSequencingExperiments(userQuery *types.Query) (int64, error)
+	GetSequencingExperiment(seqId int) (*Row, error)
+	GetFamily(seqId int) (*types.Family, error)
+	GetFamilyOccurrences(seqId int, userQuery *types.Query) ([]Row, error)
+	CountFamilyOccurrences(seqId int, userQuery *types.Query) (int64, error)
+	AggregateFamilyOccurrences(seqId int, userQuery *types.Query, options types.TermsOptions) (TermsAggregation, error)
+	GetCompoundHets(seqId int, userQuery *types.Query) ([]types.CompoundHetGene, int64, error)
+	GetCohort(cohortId string) ([]int, error)
+	CountCohortOccurrences(seqIds []int, userQuery *types.Query) ([]types.SampleCount, error)
+	GetCohortCarriers(seqIds []int, userQuery *types.Query) ([]types.LocusCarriers, error)
+	InvalidatePartCache(seqIds ...int)
+	PartCacheStats() PartCacheStats
 }
 
 type MySQLRepository struct {
-	db *gorm.DB
+	db    *gorm.DB
+	parts *PartCache
 }
 
 func New(db *gorm.DB) *MySQLRepository {
-	return &MySQLRepository{db: db}
+	return NewWithPartCache(db, NewPartCache(DefaultPartCacheTTL, DefaultPartCacheNegativeTTL))
+}
+
+func NewWithPartCache(db *gorm.DB, parts *PartCache) *MySQLRepository {
+	return &MySQLRepository{db: db, parts: parts}
 }
 
 func (r *MySQLRepository) CheckDatabaseConnection() string {
@@ -40,51 +83,118 @@ func (r *MySQLRepository) CheckDatabaseConnection() string {
 }
 
 const (
-	MinLimit = 10
-	MaxLimit = 200
+	MinLimit               = 10
+	MaxLimit               = 200
+	DefaultAggregationSize = 10
+	MaxAggregationSize     = 1000
+	MaxHistogramBuckets    = 1000
 )
 
-func (r *MySQLRepository) GetOccurrences(seqId int, userQuery *types.Query) ([]Occurrence, error) {
-	var occurrences []Occurrence
+func (r *MySQLRepository) GetOccurrences(seqId int, userQuery *types.Query) ([]Row, error) {
+	return r.getOccurrences(seqId, userQuery, nil)
+}
 
+// getOccurrences returns the occurrences of a sequencing experiment, annotated with the ones of the parents if a family is given
+func (r *MySQLRepository) getOccurrences(seqId int, userQuery *types.Query, parents *familyParts) ([]Row, error) {
 	tx, part, err := prepareQuery(seqId, userQuery, r)
 	if err != nil {
 		return nil, fmt.Errorf("error during query preparation %w", err)
 	}
-	var columns = sliceutils.Map(userQuery.SelectedFields, func(field types.Field, index int, slice []types.Field) string {
-		return fmt.Sprintf("%s.%s as %s", field.Table.Alias, field.Name, field.GetAlias())
-	})
-
-	if columns == nil {
-		columns = []string{"o.locus_id"}
+	joinParents(tx, parents)
+	selectedFields := userQuery.SelectedFields
+	if len(selectedFields) == 0 {
+		selectedFields = []types.Field{types.LocusIdField}
 	}
+	scannedFields := append(selectedFields[:len(selectedFields):len(selectedFields)], keysetFields(userQuery)...)
+	columns := selectColumns(scannedFields)
+
 	addLimitAndSort(tx, userQuery)
-	if hasFieldFromTable(userQuery.FilteredFields, types.VariantTable) || hasFieldFromTable(userQuery.SelectedFields, types.VariantTable) {
+	if joinsVariants(userQuery) {
 		// we build a TOP-N query like :
-		// SELECT o.locus_id, o.quality, o.ad_ratio, ...., v.variant_class, v.hgvsg... FROM occurrences o, variants v
+		// SELECT o.locus_id, o.quality, o.ad_ratio, ...., v.variant_class, v.hgvsg... FROM occurrences o JOIN variants v ON v.locus_id=o.locus_id
 		// WHERE o.locus_id in (
 		//	SELECT o.locus_id FROM occurrences JOIN ... WHERE quality > 100 ORDER BY ad_ratio DESC LIMIT 10
-		// ) AND o.seq_id=? AND o.part=? AND v.locus_id=o.locus_id ORDER BY ad_ratio DESC
+		// ) AND o.seq_id=? AND o.part=? ORDER BY ad_ratio DESC
 		tx = tx.Select("o.locus_id")
-		tx = r.db.Table("occurrences o, variants v").
+		tx = r.db.Table("occurrences o").
+			Joins("JOIN variants v ON v.locus_id=o.locus_id").
 			Select(columns).
-			Where("o.seq_id = ? and part=? and v.locus_id = o.locus_id and o.locus_id in (?)", seqId, part, tx)
+			Where("o.seq_id = ? and o.part=? and o.locus_id in (?)", seqId, part, tx)
+		joinParents(tx, parents)
 
 		addSort(tx, userQuery) //We re-apply the sort on the outer query
-
-		err = tx.Find(&occurrences).Error
 	} else {
-		err = tx.Select(columns).Find(&occurrences).Error
+		tx = tx.Select(columns)
 	}
+	occurrences, err := scanRows(tx, scannedFields)
 	if err != nil {
 		err = fmt.Errorf("error fetching occurrences: %w", err)
 		return nil, err
 	}
+	for i := range occurrences {
+		splitKeyset(&occurrences[i], len(selectedFields))
+	}
 
 	return occurrences, err
 
 }
 
+// selectColumns returns the columns selecting the fields by alias
+func selectColumns(fields []types.Field) []string {
+	return sliceutils.Map(fields, func(field types.Field, index int, slice []types.Field) string {
+		return fmt.Sprintf("%s as %s", field.QualifiedName(), field.GetAlias())
+	})
+}
+
+// scanRows runs the query and converts each result to a row of the selected fields
+func scanRows(tx *gorm.DB, selectedFields []types.Field) ([]Row, error) {
+	rows, err := tx.Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []Row{}
+	for rows.Next() {
+		raw := make([]interface{}, len(selectedFields))
+		pointers := make([]interface{}, len(selectedFields))
+		for i := range raw {
+			pointers[i] = &raw[i]
+		}
+		if err = rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		row, err := types.NewRow(selectedFields, raw)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// keysetFields returns the sorted fields to scan along with the selected ones when using keyset pagination
+func keysetFields(userQuery *types.Query) []types.Field {
+	if userQuery.Pagination == nil || userQuery.Pagination.Cursor == nil {
+		return nil
+	}
+	return sliceutils.Map(userQuery.SortedFields, func(sort types.SortField, index int, slice []types.SortField) types.Field {
+		field := sort.Field
+		field.Alias = fmt.Sprintf("keyset_%d", index)
+		return field
+	})
+}
+
+// splitKeyset moves the values of the keyset fields, scanned after the selected ones, to the keyset of the row
+func splitKeyset(row *Row, selectedCount int) {
+	if len(row.Values) == selectedCount {
+		return
+	}
+	row.Keyset = row.Values[selectedCount:]
+	row.Columns = row.Columns[:selectedCount]
+	row.Values = row.Values[:selectedCount]
+}
+
 func addLimitAndSort(tx *gorm.DB, userQuery *types.Query) {
 	if userQuery.Pagination != nil {
 		var l int
@@ -93,7 +203,16 @@ func addLimitAndSort(tx *gorm.DB, userQuery *types.Query) {
 		} else {
 			l = MaxLimit
 		}
-		tx = tx.Limit(l).Offset(userQuery.Pagination.Offset)
+		if cursor := userQuery.Pagination.Cursor; cursor != nil {
+			// One more row is fetched to know whether there is a next page
+			tx = tx.Limit(l + 1)
+			if cursor.Values != nil {
+				keyset, params := types.KeysetFilterToSQL(userQuery.SortedFields, cursor.Values)
+				tx = tx.Where(keyset, params...)
+			}
+		} else {
+			tx = tx.Limit(l).Offset(userQuery.Pagination.Offset)
+		}
 	} else {
 		tx = tx.Limit(MinLimit)
 	}
@@ -102,7 +221,7 @@ func addLimitAndSort(tx *gorm.DB, userQuery *types.Query) {
 
 func addSort(tx *gorm.DB, userQuery *types.Query) {
 	for _, sort := range userQuery.SortedFields {
-		s := fmt.Sprintf("%s.%s %s", sort.Field.Table.Alias, sort.Field.GetAlias(), sort.Order)
+		s := fmt.Sprintf("%s %s", sort.Field.QualifiedName(), sort.Order)
 		tx = tx.Order(s)
 	}
 }
@@ -112,9 +231,14 @@ func prepareQuery(seqId int, userQuery *types.Query, r *MySQLRepository) (*gorm.
 	if err != nil {
 		return nil, 0, fmt.Errorf("error during partition fetch %w", err)
 	}
-	tx := r.db.Table("occurrences o").Where("o.seq_id = ? and part=? and has_alt", seqId, part)
+	return buildQuery(r.db, seqId, part, userQuery), part, nil
+}
+
+// buildQuery returns the query on the occurrences of a sequencing experiment matching the filters of the user query
+func buildQuery(db *gorm.DB, seqId int, part int, userQuery *types.Query) *gorm.DB {
+	tx := db.Table("occurrences o").Where("o.seq_id = ? and o.part=? and o.has_alt", seqId, part)
 	if userQuery != nil {
-		if hasFieldFromTable(userQuery.FilteredFields, types.VariantTable) || hasFieldFromTable(userQuery.SelectedFields, types.VariantTable) {
+		if joinsVariants(userQuery) {
 			tx = tx.Joins("JOIN variants v ON v.locus_id=o.locus_id")
 		}
 
@@ -124,7 +248,17 @@ func prepareQuery(seqId int, userQuery *types.Query, r *MySQLRepository) (*gorm.
 
 		}
 	}
-	return tx, part, nil
+	return tx
+}
+
+// joinsVariants returns whether the query needs the variants table to filter, select or sort
+func joinsVariants(userQuery *types.Query) bool {
+	sortedFields := sliceutils.Map(userQuery.SortedFields, func(sort types.SortField, index int, slice []types.SortField) types.Field {
+		return sort.Field
+	})
+	return hasFieldFromTable(userQuery.FilteredFields, types.VariantTable) ||
+		hasFieldFromTable(userQuery.SelectedFields, types.VariantTable) ||
+		hasFieldFromTable(sortedFields, types.VariantTable)
 }
 
 func hasFieldFromTable(fields []types.Field, table types.Table) bool {
@@ -147,27 +281,292 @@ func (r *MySQLRepository) CountOccurrences(seqId int, userQuery *types.Query) (i
 
 }
 
-func (r *MySQLRepository) GetPart(seqId int) (int, error) { //TODO cache
-	tx := r.db.Table("sequencing_experiment").Where("seq_id = ?", seqId).Select("part")
-	var part int
-	err := tx.Scan(&part).Error
+// GetPart returns the partition of the occurrences of a sequencing experiment, or ErrNotFound if it does not exist
+func (r *MySQLRepository) GetPart(seqId int) (int, error) {
+	part, found, ok := r.parts.get(seqId)
+	if !ok {
+		tx := r.db.Table("sequencing_experiment").Where("seq_id = ?", seqId).Select("part")
+		var parts []int
+		err := tx.Find(&parts).Error
+		if err != nil {
+			return 0, fmt.Errorf("error fetching part: %w", err)
+		}
+		found = len(parts) > 0
+		if found {
+			part = parts[0]
+		}
+		r.parts.set(seqId, part, found)
+	}
+	if !found {
+		return 0, fmt.Errorf("sequencing experiment %d: %w", seqId, ErrNotFound)
+	}
+	return part, nil
+}
+
+// WarmUpPartCache caches the partitions of all the sequencing experiments and returns their number
+func (r *MySQLRepository) WarmUpPartCache() (int, error) {
+	var experiments []struct {
+		SeqId int
+		Part  int
+	}
+	err := r.db.Table("sequencing_experiment").Select("seq_id, part").Find(&experiments).Error
 	if err != nil {
-		return part, fmt.Errorf("error fetching part: %w", err)
+		return 0, fmt.Errorf("error fetching partitions: %w", err)
 	}
-	return part, err
+	for _, experiment := range experiments {
+		r.parts.set(experiment.SeqId, experiment.Part, true)
+	}
+	return len(experiments), nil
 }
 
-func (r *MySQLRepository) AggregateOccurrences(seqId int, userQuery *types.Query) ([]Aggregation, error) {
-	tx, _, err := prepareQuery(seqId, userQuery, r)
-	var aggregation []Aggregation
+// InvalidatePartCache forgets the partitions of the given sequencing experiments, or of all of them when none is given
+func (r *MySQLRepository) InvalidatePartCache(seqIds ...int) {
+	r.parts.Invalidate(seqIds...)
+}
+
+func (r *MySQLRepository) PartCacheStats() PartCacheStats {
+	return r.parts.Stats()
+}
+
+func (r *MySQLRepository) AggregateOccurrences(seqId int, userQuery *types.Query, options types.TermsOptions) (TermsAggregation, error) {
+	part, err := r.GetPart(seqId)
 	if err != nil {
-		return aggregation, fmt.Errorf("error during query preparation %w", err)
+		return TermsAggregation{}, fmt.Errorf("error during partition fetch %w", err)
+	}
+	return aggregateTerms(func() *gorm.DB {
+		return buildQuery(r.db, seqId, part, userQuery)
+	}, userQuery.SelectedFields[0], options)
+}
+
+// aggregateTerms counts the rows of the queries returned by newQuery by value of the field
+func aggregateTerms(newQuery func() *gorm.DB, field types.Field, options types.TermsOptions) (TermsAggregation, error) {
+	var aggregation TermsAggregation
+	aggCol := field.QualifiedName()
+
+	size := options.Size
+	if size <= 0 {
+		size = DefaultAggregationSize
+	} else if size > MaxAggregationSize {
+		size = MaxAggregationSize
+	}
+	var order string
+	switch options.Order {
+	case types.OrderByCountAsc:
+		order = "count asc, bucket asc"
+	case types.OrderByKeyAsc:
+		order = "bucket asc"
+	case types.OrderByKeyDesc:
+		order = "bucket desc"
+	default:
+		order = "count desc, bucket asc"
+	}
+
+	tx := newQuery()
+	bucketCol := aggCol
+	if field.IsArray {
+		// Array fields are aggregated by element
+		tx = tx.Joins(fmt.Sprintf("CROSS JOIN LATERAL unnest(%s) AS unnest", aggCol))
+		bucketCol = "unnest"
 	}
-	aggCol := userQuery.SelectedFields[0].Name
-	sel := fmt.Sprintf("%s as bucket, count(1) as count", aggCol)
-	err = tx.Select(sel).Group(aggCol).Find(&aggregation).Error
+	sel := fmt.Sprintf("%s as bucket, count(1) as count", bucketCol)
+	err := tx.Select(sel).Where(fmt.Sprintf("%s IS NOT NULL", bucketCol)).Group(bucketCol).Order(order).Limit(size).Find(&aggregation.Buckets).Error
 	if err != nil {
 		return aggregation, fmt.Errorf("error query aggragation: %w", err)
 	}
+
+	var totals struct {
+		NonNull int64
+		Missing int64
+	}
+	sel = fmt.Sprintf("count(%s) as non_null, count(1) - count(%s) as missing", aggCol, aggCol)
+	if field.IsArray {
+		sel = fmt.Sprintf("coalesce(sum(array_length(%s)), 0) as non_null, coalesce(sum(case when array_length(%s) > 0 then 0 else 1 end), 0) as missing", aggCol, aggCol)
+	}
+	err = newQuery().Select(sel).Scan(&totals).Error
+	if err != nil {
+		return aggregation, fmt.Errorf("error query aggragation totals: %w", err)
+	}
+	aggregation.OtherCount = totals.NonNull
+	for _, bucket := range aggregation.Buckets {
+		aggregation.OtherCount -= bucket.Count
+	}
+	if options.Missing {
+		aggregation.Missing = &totals.Missing
+	}
+	if aggregation.Buckets == nil {
+		aggregation.Buckets = []Aggregation{}
+	}
 	return aggregation, err
 }
+
+func (r *MySQLRepository) HistogramOccurrences(seqId int, userQuery *types.Query, options types.HistogramOptions) (Histogram, error) {
+	histogram := Histogram{Buckets: []types.HistogramBucket{}}
+	part, err := r.GetPart(seqId)
+	if err != nil {
+		return histogram, fmt.Errorf("error during partition fetch %w", err)
+	}
+	field := userQuery.SelectedFields[0]
+	col := field.QualifiedName()
+	tx := buildQuery(r.db, seqId, part, userQuery).Where(fmt.Sprintf("%s IS NOT NULL", col))
+
+	if len(options.Ranges) > 0 {
+		counts, err := countRanges(tx, col, options.Ranges)
+		if err != nil {
+			return histogram, fmt.Errorf("error query histogram: %w", err)
+		}
+		for i, rng := range options.Ranges {
+			histogram.Buckets = append(histogram.Buckets, types.HistogramBucket{Range: rng, Count: counts[i]})
+		}
+		return histogram, nil
+	}
+
+	// Buckets are numbered by floor(value / interval), values lower or equal to 0 having no bucket on a log scale
+	scaled := fmt.Sprintf("%s / ?", col)
+	if options.LogScale {
+		scaled = fmt.Sprintf("case when %s > 0 then log10(%s) / ? end", col, col)
+	}
+	bucket := fmt.Sprintf("floor(%s)", scaled)
+	// The bounds are floored here rather than in the query, as the bucket numbers of a tiny interval overflow a bigint
+	var bounds struct {
+		MinValue *float64
+		MaxValue *float64
+	}
+	sel := fmt.Sprintf("min(%s) as min_value, max(%s) as max_value", scaled, scaled)
+	err = tx.Select(sel, options.Interval, options.Interval).Scan(&bounds).Error
+	if err != nil {
+		return histogram, fmt.Errorf("error query histogram bounds: %w", err)
+	}
+	if bounds.MinValue != nil {
+		minBucket, maxBucket := math.Floor(*bounds.MinValue), math.Floor(*bounds.MaxValue)
+		if minBucket < math.MinInt64 || maxBucket >= math.MaxInt64 {
+			return histogram, fmt.Errorf("bucket numbers overflow: %w", ErrTooManyBuckets)
+		}
+		if maxBucket-minBucket >= MaxHistogramBuckets {
+			return histogram, fmt.Errorf("%.0f buckets: %w", maxBucket-minBucket+1, ErrTooManyBuckets)
+		}
+	}
+
+	var buckets []struct {
+		Bucket *int64
+		Count  int64
+	}
+	tx = buildQuery(r.db, seqId, part, userQuery).Where(fmt.Sprintf("%s IS NOT NULL", col))
+	sel = fmt.Sprintf("cast(%s as bigint) as bucket, count(1) as count", bucket)
+	err = tx.Select(sel, options.Interval).Group("bucket").Order("bucket asc").Find(&buckets).Error
+	if err != nil {
+		return histogram, fmt.Errorf("error query histogram: %w", err)
+	}
+	if options.LogScale {
+		histogram.ZeroCount = new(int64)
+	}
+	for _, b := range buckets {
+		if b.Bucket == nil {
+			// Only values lower or equal to 0 on a log scale have no bucket
+			if histogram.ZeroCount != nil {
+				*histogram.ZeroCount = b.Count
+			}
+			continue
+		}
+		from := bucketBound(*b.Bucket, options)
+		to := bucketBound(*b.Bucket+1, options)
+		histogram.Buckets = append(histogram.Buckets, types.HistogramBucket{Range: types.Range{From: &from, To: &to}, Count: b.Count})
+	}
+	return histogram, nil
+}
+
+// countRanges counts the values of the column in each range with a single query
+func countRanges(tx *gorm.DB, col string, ranges []types.Range) ([]int64, error) {
+	var (
+		columns []string
+		params  []interface{}
+	)
+	for i, rng := range ranges {
+		var conditions []string
+		if rng.From != nil {
+			conditions = append(conditions, fmt.Sprintf("%s >= ?", col))
+			params = append(params, *rng.From)
+		}
+		if rng.To != nil {
+			conditions = append(conditions, fmt.Sprintf("%s < ?", col))
+			params = append(params, *rng.To)
+		}
+		columns = append(columns, fmt.Sprintf("coalesce(sum(case when %s then 1 else 0 end), 0) as range_%d", strings.Join(conditions, " and "), i))
+	}
+	counts := make([]int64, len(ranges))
+	pointers := make([]interface{}, len(ranges))
+	for i := range counts {
+		pointers[i] = &counts[i]
+	}
+	err := tx.Select(strings.Join(columns, ", "), params...).Row().Scan(pointers...)
+	return counts, err
+}
+
+// bucketBound returns the lower bound of a histogram bucket, rounded to absorb floating point errors
+func bucketBound(bucket int64, options types.HistogramOptions) float64 {
+	bound := float64(bucket) * options.Interval
+	if options.LogScale {
+		bound = math.Pow(10, bound)
+	}
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(bound, 'g', 12, 64), 64)
+	return rounded
+}
+
+// StatsOccurrences computes the statistics of each selected field with a single query
+func (r *MySQLRepository) StatsOccurrences(seqId int, userQuery *types.Query, percentiles []float64) (map[string]FieldStats, error) {
+	tx, _, err := prepareQuery(seqId, userQuery, r)
+	if err != nil {
+		return nil, fmt.Errorf("error during query preparation %w", err)
+	}
+	var (
+		columns []string
+		params  []interface{}
+	)
+	for _, field := range userQuery.SelectedFields {
+		col := field.QualifiedName()
+		columns = append(columns,
+			fmt.Sprintf("count(%s)", col),
+			fmt.Sprintf("min(%s)", col),
+			fmt.Sprintf("max(%s)", col),
+			fmt.Sprintf("avg(%s)", col),
+			fmt.Sprintf("stddev(%s)", col),
+		)
+		for _, p := range percentiles {
+			columns = append(columns, fmt.Sprintf("percentile_approx(cast(%s as double), ?)", col))
+			params = append(params, p)
+		}
+	}
+	values := make([]sql.NullFloat64, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	err = tx.Select(strings.Join(columns, ", "), params...).Row().Scan(pointers...)
+	if err != nil {
+		return nil, fmt.Errorf("error query stats: %w", err)
+	}
+
+	stats := make(map[string]FieldStats, len(userQuery.SelectedFields))
+	for _, field := range userQuery.SelectedFields {
+		s := FieldStats{
+			Count:       int64(values[0].Float64),
+			Min:         nullableFloat(values[1]),
+			Max:         nullableFloat(values[2]),
+			Mean:        nullableFloat(values[3]),
+			Stddev:      nullableFloat(values[4]),
+			Percentiles: []types.Percentile{},
+		}
+		for i, p := range percentiles {
+			s.Percentiles = append(s.Percentiles, types.Percentile{Percentile: p, Value: nullableFloat(values[5+i])})
+		}
+		stats[field.GetAlias()] = s
+		values = values[5+len(percentiles):]
+	}
+	return stats, nil
+}
+
+func nullableFloat(value sql.NullFloat64) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Float64
+}
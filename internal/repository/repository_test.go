@@ -29,15 +29,15 @@ func TestGetOccurrences(t *testing.T) {
 		occurrences, err := repo.GetOccurrences(1, &query)
 		assert.NoError(t, err)
 		if assert.Len(t, occurrences, 1) {
-			assert.Equal(t, 1, occurrences[0].SeqId)
-			assert.EqualValues(t, 1000, occurrences[0].LocusId)
-			assert.Equal(t, "PASS", occurrences[0].Filter)
-			assert.Equal(t, "HET", occurrences[0].Zygosity)
-			assert.Equal(t, 0.99, occurrences[0].Pf)
-			assert.Equal(t, 0.01, occurrences[0].Af)
-			assert.Equal(t, "hgvsg1", occurrences[0].Hgvsg)
-			assert.Equal(t, 1.0, occurrences[0].AdRatio)
-			assert.Equal(t, "class1", occurrences[0].VariantClass)
+			assert.EqualValues(t, 1, occurrences[0].Get("seq_id"))
+			assert.EqualValues(t, 1000, occurrences[0].Get("locus_id"))
+			assert.Equal(t, "PASS", occurrences[0].Get("filter"))
+			assert.Equal(t, "HET", occurrences[0].Get("zygosity"))
+			assert.Equal(t, 0.99, occurrences[0].Get("pf"))
+			assert.Equal(t, 0.01, occurrences[0].Get("af"))
+			assert.Equal(t, "hgvsg1", occurrences[0].Get("hgvsg"))
+			assert.Equal(t, 1.0, occurrences[0].Get("ad_ratio"))
+			assert.Equal(t, "class1", occurrences[0].Get("variant_class"))
 		}
 	})
 }
@@ -52,10 +52,10 @@ func TestGetOccurrencesWithPartialColumns(t *testing.T) {
 		occurrences, err := repo.GetOccurrences(1, &query)
 		assert.NoError(t, err)
 		if assert.Len(t, occurrences, 1) {
-			assert.Equal(t, 1, occurrences[0].SeqId)
-			assert.EqualValues(t, 1000, occurrences[0].LocusId)
-			assert.Equal(t, "PASS", occurrences[0].Filter)
-			assert.Empty(t, occurrences[0].VepImpact)
+			assert.EqualValues(t, 1, occurrences[0].Get("seq_id"))
+			assert.EqualValues(t, 1000, occurrences[0].Get("locus_id"))
+			assert.Equal(t, "PASS", occurrences[0].Get("filter"))
+			assert.Equal(t, []string{"seq_id", "locus_id", "ad_ratio", "filter"}, occurrences[0].Columns)
 		}
 	})
 }
@@ -70,8 +70,8 @@ func TestGetOccurrencesWithNoColumns(t *testing.T) {
 		assert.Len(t, occurrences, 1)
 
 		if assert.Len(t, occurrences, 1) {
-			assert.EqualValues(t, 1000, occurrences[0].LocusId)
-			assert.Empty(t, occurrences[0].Filter)
+			assert.EqualValues(t, 1000, occurrences[0].Get("locus_id"))
+			assert.Equal(t, []string{"locus_id"}, occurrences[0].Columns)
 		}
 	})
 }
@@ -106,6 +106,27 @@ func TestCountOccurrencesFilter(t *testing.T) {
 	})
 }
 
+func TestCountOccurrencesArrayFilter(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "simple", func(t *testing.T, db *gorm.DB) {
+
+		repo := New(db)
+
+		query := types.Query{
+			Filters: &types.ComparisonNode{
+				Operator: "in",
+				Value:    []interface{}{"interpretation1", "interpretation2"},
+				Field:    types.ClinvarInterpretationField,
+			},
+			FilteredFields: []types.Field{types.ClinvarInterpretationField},
+		}
+		c, err := repo.CountOccurrences(1, &query)
+
+		if assert.NoError(t, err) {
+			assert.EqualValues(t, 1, c)
+		}
+	})
+}
+
 func TestGetOccurrencesFilter(t *testing.T) {
 	testutils.ParallelTestWithDb(t, "multiple", func(t *testing.T, db *gorm.DB) {
 
@@ -122,15 +143,15 @@ func TestGetOccurrencesFilter(t *testing.T) {
 		occurrences, err := repo.GetOccurrences(1, &query)
 		assert.NoError(t, err)
 		if assert.Len(t, occurrences, 1) {
-			assert.Equal(t, 1, occurrences[0].SeqId)
-			assert.EqualValues(t, 1000, occurrences[0].LocusId)
-			assert.Equal(t, "PASS", occurrences[0].Filter)
-			assert.Equal(t, "HET", occurrences[0].Zygosity)
-			assert.Equal(t, 0.99, occurrences[0].Pf)
-			assert.Equal(t, 0.01, occurrences[0].Af)
-			assert.Equal(t, "hgvsg1", occurrences[0].Hgvsg)
-			assert.Equal(t, 1.0, occurrences[0].AdRatio)
-			assert.Equal(t, "class1", occurrences[0].VariantClass)
+			assert.EqualValues(t, 1, occurrences[0].Get("seq_id"))
+			assert.EqualValues(t, 1000, occurrences[0].Get("locus_id"))
+			assert.Equal(t, "PASS", occurrences[0].Get("filter"))
+			assert.Equal(t, "HET", occurrences[0].Get("zygosity"))
+			assert.Equal(t, 0.99, occurrences[0].Get("pf"))
+			assert.Equal(t, 0.01, occurrences[0].Get("af"))
+			assert.Equal(t, "hgvsg1", occurrences[0].Get("hgvsg"))
+			assert.Equal(t, 1.0, occurrences[0].Get("ad_ratio"))
+			assert.Equal(t, "class1", occurrences[0].Get("variant_class"))
 		}
 	})
 }
@@ -173,8 +194,185 @@ func TestGetOccurrencesLimitAndOffset(t *testing.T) {
 		occurrences, err := repo.GetOccurrences(1, &query)
 		assert.NoError(t, err)
 		if assert.Len(t, occurrences, 12) {
-			assert.EqualValues(t, 1023, occurrences[0].LocusId)
-			assert.EqualValues(t, 1012, occurrences[len(occurrences)-1].LocusId)
+			assert.EqualValues(t, 1023, occurrences[0].Get("locus_id"))
+			assert.EqualValues(t, 1012, occurrences[len(occurrences)-1].Get("locus_id"))
+		}
+	})
+}
+
+func TestGetOccurrencesCursor(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "pagination", func(t *testing.T, db *gorm.DB) {
+
+		repo := New(db)
+
+		sortedFields := []types.SortField{
+			{Field: types.PfField, Order: "desc"},
+			{Field: types.LocusIdField, Order: "asc"},
+		}
+		query := types.Query{
+			SelectedFields: []types.Field{types.LocusIdField},
+			Pagination:     &types.Pagination{Limit: 5, Cursor: &types.Cursor{}},
+			SortedFields:   sortedFields,
+		}
+		firstPage, err := repo.GetOccurrences(1, &query)
+		assert.NoError(t, err)
+		// One more row than the limit is returned when there is a next page
+		if assert.Len(t, firstPage, 6) {
+			assert.EqualValues(t, 1028, firstPage[0].Get("locus_id"))
+			assert.Equal(t, []string{"locus_id"}, firstPage[4].Columns)
+			assert.Equal(t, []interface{}{0.25, int64(1024)}, firstPage[4].Keyset)
+		}
+
+		query.Pagination.Cursor = &types.Cursor{Values: firstPage[4].Keyset}
+		secondPage, err := repo.GetOccurrences(1, &query)
+		assert.NoError(t, err)
+		if assert.Len(t, secondPage, 6) {
+			assert.EqualValues(t, 1023, secondPage[0].Get("locus_id"))
+			assert.EqualValues(t, 1019, secondPage[4].Get("locus_id"))
+		}
+	})
+}
+
+func TestAggregateOccurrencesSize(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "aggregation", func(t *testing.T, db *gorm.DB) {
+
+		repo := New(db)
+
+		query := types.Query{
+			SelectedFields: []types.Field{types.ZygosityField},
+		}
+		aggregation, err := repo.AggregateOccurrences(1, &query, types.TermsOptions{Size: 1, Missing: true})
+		if assert.NoError(t, err) {
+			assert.Equal(t, []Aggregation{{Bucket: "HET", Count: 3}}, aggregation.Buckets)
+			assert.EqualValues(t, 1, aggregation.OtherCount)
+			if assert.NotNil(t, aggregation.Missing) {
+				assert.EqualValues(t, 0, *aggregation.Missing)
+			}
+		}
+	})
+}
+
+func TestAggregateOccurrencesOrderByKey(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "aggregation", func(t *testing.T, db *gorm.DB) {
+
+		repo := New(db)
+
+		query := types.Query{
+			SelectedFields: []types.Field{types.ZygosityField},
+		}
+		aggregation, err := repo.AggregateOccurrences(1, &query, types.TermsOptions{Order: types.OrderByKeyDesc})
+		if assert.NoError(t, err) {
+			assert.Equal(t, []Aggregation{{Bucket: "HOM", Count: 1}, {Bucket: "HET", Count: 3}}, aggregation.Buckets)
+			assert.EqualValues(t, 0, aggregation.OtherCount)
+			assert.Nil(t, aggregation.Missing)
+		}
+	})
+}
+
+func TestHistogramOccurrencesInterval(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "qc", func(t *testing.T, db *gorm.DB) {
+
+		repo := New(db)
+
+		query := types.Query{
+			SelectedFields: []types.Field{types.DpField},
+		}
+		histogram, err := repo.HistogramOccurrences(1, &query, types.HistogramOptions{Interval: 10})
+		if assert.NoError(t, err) && assert.Len(t, histogram.Buckets, 2) {
+			assert.Equal(t, 0.0, *histogram.Buckets[0].From)
+			assert.Equal(t, 10.0, *histogram.Buckets[0].To)
+			assert.EqualValues(t, 1, histogram.Buckets[0].Count)
+			assert.Equal(t, 30.0, *histogram.Buckets[1].From)
+			assert.Equal(t, 40.0, *histogram.Buckets[1].To)
+			assert.EqualValues(t, 2, histogram.Buckets[1].Count)
+			assert.Nil(t, histogram.ZeroCount)
+		}
+	})
+}
+
+func TestHistogramOccurrencesTooManyBuckets(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "qc", func(t *testing.T, db *gorm.DB) {
+
+		repo := New(db)
+
+		query := types.Query{
+			SelectedFields: []types.Field{types.DpField},
+		}
+		_, err := repo.HistogramOccurrences(1, &query, types.HistogramOptions{Interval: 0.01})
+		assert.ErrorIs(t, err, ErrTooManyBuckets)
+	})
+}
+
+func TestHistogramOccurrencesBucketOverflow(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "qc", func(t *testing.T, db *gorm.DB) {
+
+		repo := New(db)
+
+		query := types.Query{
+			SelectedFields: []types.Field{types.DpField},
+		}
+		_, err := repo.HistogramOccurrences(1, &query, types.HistogramOptions{Interval: 1e-300})
+		assert.ErrorIs(t, err, ErrTooManyBuckets)
+	})
+}
+
+func TestHistogramOccurrencesRanges(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "qc", func(t *testing.T, db *gorm.DB) {
+
+		repo := New(db)
+
+		query := types.Query{
+			SelectedFields: []types.Field{types.DpField},
+		}
+		ten := 10.0
+		ranges := []types.Range{{To: &ten}, {From: &ten}}
+		histogram, err := repo.HistogramOccurrences(1, &query, types.HistogramOptions{Ranges: ranges})
+		if assert.NoError(t, err) && assert.Len(t, histogram.Buckets, 2) {
+			assert.EqualValues(t, 1, histogram.Buckets[0].Count)
+			assert.EqualValues(t, 2, histogram.Buckets[1].Count)
+		}
+	})
+}
+
+func TestHistogramOccurrencesLogScale(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "qc", func(t *testing.T, db *gorm.DB) {
+
+		repo := New(db)
+
+		query := types.Query{
+			SelectedFields: []types.Field{types.AfField},
+		}
+		histogram, err := repo.HistogramOccurrences(1, &query, types.HistogramOptions{Interval: 1, LogScale: true})
+		if assert.NoError(t, err) && assert.Len(t, histogram.Buckets, 1) {
+			assert.Equal(t, 0.1, *histogram.Buckets[0].From)
+			assert.Equal(t, 1.0, *histogram.Buckets[0].To)
+			assert.EqualValues(t, 2, histogram.Buckets[0].Count)
+			assert.EqualValues(t, 1, *histogram.ZeroCount)
+		}
+	})
+}
+
+func TestStatsOccurrences(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "qc", func(t *testing.T, db *gorm.DB) {
+
+		repo := New(db)
+
+		query := types.Query{
+			SelectedFields: []types.Field{types.DpField, types.GnomadV3AfField},
+		}
+		stats, err := repo.StatsOccurrences(1, &query, []float64{0.5})
+		if assert.NoError(t, err) {
+			dp := stats["dp"]
+			assert.EqualValues(t, 3, dp.Count)
+			assert.Equal(t, 5.0, *dp.Min)
+			assert.Equal(t, 30.0, *dp.Max)
+			assert.InDelta(t, 21.67, *dp.Mean, 0.01)
+			assert.InDelta(t, 30.0, *dp.Percentiles[0].Value, 0.5)
+
+			gnomad := stats["gnomad_v3_af"]
+			assert.EqualValues(t, 0, gnomad.Count)
+			assert.Nil(t, gnomad.Min)
+			assert.Nil(t, gnomad.Mean)
 		}
 	})
 }
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"go-poc/internal/types"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultResultCacheSize = 1000
+	DefaultResultCacheTTL  = 5 * time.Minute
+)
+
+// ResultCacheStats gives the usage of the result cache since the start of the application
+type ResultCacheStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+	Size   int   `json:"size"`
+}
+
+// ResultCache is a LRU cache of query results with a TTL. Cached results are shared and must not be modified.
+type ResultCache struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // Entries from the most to the least recently used
+	size    int
+	ttl     time.Duration
+	hits    int64
+	misses  int64
+	now     func() time.Time
+}
+
+type resultEntry struct {
+	key       string
+	seqId     int
+	value     interface{}
+	expiresAt time.Time
+}
+
+func NewResultCache(size int, ttl time.Duration) *ResultCache {
+	return &ResultCache{entries: map[string]*list.Element{}, lru: list.New(), size: size, ttl: ttl, now: time.Now}
+}
+
+// get returns the result cached for the key, ok is false when not cached or expired
+func (c *ResultCache) get(key string) (value interface{}, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if ok && !c.now().Before(element.Value.(*resultEntry).expiresAt) {
+		c.remove(element)
+		ok = false
+	}
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.lru.MoveToFront(element)
+	return element.Value.(*resultEntry).value, true
+}
+
+// set caches the result of a query on the sequencing experiment, evicting the least recently used results above the size
+func (c *ResultCache) set(key string, seqId int, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+	entry := &resultEntry{key: key, seqId: seqId, value: value, expiresAt: c.now().Add(c.ttl)}
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.size {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *ResultCache) remove(element *list.Element) {
+	c.lru.Remove(element)
+	delete(c.entries, element.Value.(*resultEntry).key)
+}
+
+// InvalidateSeqId removes the results of the queries on a sequencing experiment, e.g. when its data is reloaded
+func (c *ResultCache) InvalidateSeqId(seqId int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for element := c.lru.Front(); element != nil; {
+		next := element.Next()
+		if element.Value.(*resultEntry).seqId == seqId {
+			c.remove(element)
+		}
+		element = next
+	}
+}
+
+// Invalidate removes all the cached results
+func (c *ResultCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string]*list.Element{}
+	c.lru.Init()
+}
+
+func (c *ResultCache) Stats() ResultCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return ResultCacheStats{Hits: c.hits, Misses: c.misses, Size: c.lru.Len()}
+}
+
+// CachedRepository caches the results of the occurrence queries of a sequencing experiment, the other queries being
+// delegated to the wrapped repository
+type CachedRepository struct {
+	Repository
+	cache    *ResultCache
+	onLookup func(hit bool) // Called on each lookup, e.g. to report whether a request was served from the cache
+}
+
+func NewCachedRepository(repo Repository, cache *ResultCache, onLookup func(hit bool)) *CachedRepository {
+	return &CachedRepository{Repository: repo, cache: cache, onLookup: onLookup}
+}
+
+func (r *CachedRepository) GetOccurrences(seqId int, userQuery *types.Query) ([]Row, error) {
+	return cached(r, "occurrences.list", seqId, userQuery, nil, func() ([]Row, error) {
+		return r.Repository.GetOccurrences(seqId, userQuery)
+	})
+}
+
+func (r *CachedRepository) CountOccurrences(seqId int, userQuery *types.Query) (int64, error) {
+	return cached(r, "occurrences.count", seqId, userQuery, nil, func() (int64, error) {
+		return r.Repository.CountOccurrences(seqId, userQuery)
+	})
+}
+
+func (r *CachedRepository) AggregateOccurrences(seqId int, userQuery *types.Query, options types.TermsOptions) (TermsAggregation, error) {
+	return cached(r, "occurrences.aggregate", seqId, userQuery, options, func() (TermsAggregation, error) {
+		return r.Repository.AggregateOccurrences(seqId, userQuery, options)
+	})
+}
+
+func (r *CachedRepository) HistogramOccurrences(seqId int, userQuery *types.Query, options types.HistogramOptions) (Histogram, error) {
+	return cached(r, "occurrences.histogram", seqId, userQuery, options, func() (Histogram, error) {
+		return r.Repository.HistogramOccurrences(seqId, userQuery, options)
+	})
+}
+
+func (r *CachedRepository) StatsOccurrences(seqId int, userQuery *types.Query, percentiles []float64) (map[string]FieldStats, error) {
+	return cached(r, "occurrences.stats", seqId, userQuery, percentiles, func() (map[string]FieldStats, error) {
+		return r.Repository.StatsOccurrences(seqId, userQuery, percentiles)
+	})
+}
+
+// cached returns the cached result of the query, or fetches and caches it. Errors are not cached, nor the results of
+// queries whose options cannot be encoded in a key.
+func cached[T any](r *CachedRepository, method string, seqId int, userQuery *types.Query, options interface{}, fetch func() (T, error)) (T, error) {
+	key, err := resultKey(method, seqId, userQuery, options)
+	if err != nil {
+		r.lookup(false)
+		return fetch()
+	}
+	if value, ok := r.cache.get(key); ok {
+		r.lookup(true)
+		return value.(T), nil
+	}
+	r.lookup(false)
+	value, err := fetch()
+	if err == nil {
+		r.cache.set(key, seqId, value)
+	}
+	return value, err
+}
+
+func (r *CachedRepository) lookup(hit bool) {
+	if r.onLookup != nil {
+		r.onLookup(hit)
+	}
+}
+
+// resultKey identifies the result of a query of a repository method on a sequencing experiment with the given options
+func resultKey(method string, seqId int, userQuery *types.Query, options interface{}) (string, error) {
+	encoded, err := json.Marshal(options)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:%d:%s:%s", method, seqId, userQuery.Fingerprint(), encoded), nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go-poc/internal/types"
+	"math"
+	"testing"
+	"time"
+)
+
+// countingRepository counts the occurrences queries reaching the database
+type countingRepository struct {
+	Repository
+	calls int
+	err   error
+}
+
+func (r *countingRepository) CountOccurrences(seqId int, _ *types.Query) (int64, error) {
+	r.calls++
+	return int64(seqId * 10), r.err
+}
+
+func (r *countingRepository) StatsOccurrences(int, *types.Query, []float64) (map[string]FieldStats, error) {
+	r.calls++
+	return map[string]FieldStats{}, r.err
+}
+
+func TestResultCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+	cache := NewResultCache(2, time.Hour)
+	cache.set("a", 1, 1)
+	cache.set("b", 1, 2)
+	_, ok := cache.get("a")
+	assert.True(t, ok)
+	cache.set("c", 2, 3)
+
+	_, ok = cache.get("b")
+	assert.False(t, ok)
+	value, ok := cache.get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	assert.Equal(t, ResultCacheStats{Hits: 2, Misses: 1, Size: 2}, cache.Stats())
+}
+
+func TestResultCacheExpires(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewResultCache(10, time.Minute)
+	cache.now = func() time.Time { return now }
+	cache.set("a", 1, 1)
+
+	now = now.Add(2 * time.Minute)
+	_, ok := cache.get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, cache.Stats().Size)
+}
+
+func TestResultCacheInvalidateSeqId(t *testing.T) {
+	t.Parallel()
+	cache := NewResultCache(10, time.Hour)
+	cache.set("a", 1, 1)
+	cache.set("b", 2, 2)
+	cache.set("c", 1, 3)
+
+	cache.InvalidateSeqId(1)
+	_, ok := cache.get("a")
+	assert.False(t, ok)
+	_, ok = cache.get("b")
+	assert.True(t, ok)
+
+	cache.Invalidate()
+	assert.Equal(t, 0, cache.Stats().Size)
+}
+
+func TestCachedRepository(t *testing.T) {
+	t.Parallel()
+	repo := &countingRepository{}
+	var lookups []bool
+	cached := NewCachedRepository(repo, NewResultCache(10, time.Hour), func(hit bool) { lookups = append(lookups, hit) })
+	query := types.Query{
+		Filters:        &types.ComparisonNode{Operator: "in", Value: "HET", Field: types.ZygosityField},
+		FilteredFields: []types.Field{types.ZygosityField},
+	}
+	equivalent := query
+
+	count, err := cached.CountOccurrences(1, &query)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 10, count)
+	count, err = cached.CountOccurrences(1, &equivalent)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 10, count)
+	count, err = cached.CountOccurrences(2, &query)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 20, count)
+
+	assert.Equal(t, 2, repo.calls)
+	assert.Equal(t, []bool{false, true, false}, lookups)
+}
+
+func TestCachedRepositoryDoesNotCacheErrors(t *testing.T) {
+	t.Parallel()
+	repo := &countingRepository{err: errors.New("boom")}
+	cached := NewCachedRepository(repo, NewResultCache(10, time.Hour), nil)
+
+	_, err := cached.CountOccurrences(1, &types.Query{})
+	assert.Error(t, err)
+	_, err = cached.CountOccurrences(1, &types.Query{})
+	assert.Error(t, err)
+	assert.Equal(t, 2, repo.calls)
+}
+
+func TestCachedRepositoryWithoutKey(t *testing.T) {
+	t.Parallel()
+	repo := &countingRepository{}
+	var lookups []bool
+	cached := NewCachedRepository(repo, NewResultCache(10, time.Hour), func(hit bool) { lookups = append(lookups, hit) })
+
+	// NaN cannot be encoded in JSON
+	percentiles := []float64{math.NaN()}
+	_, err := cached.StatsOccurrences(1, &types.Query{}, percentiles)
+	assert.NoError(t, err)
+	_, err = cached.StatsOccurrences(1, &types.Query{}, percentiles)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, repo.calls)
+	assert.Equal(t, []bool{false, false}, lookups)
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/Goldziher/go-utils/sliceutils"
+	"go-poc/internal/types"
+	"gorm.io/gorm"
+)
+
+func (r *MySQLRepository) GetSequencingExperiments(userQuery *types.Query) ([]Row, error) {
+	selectedFields := userQuery.SelectedFields
+	if len(selectedFields) == 0 {
+		selectedFields = []types.Field{types.ExperimentSeqIdField}
+	}
+	tx := buildSequencingExperimentsQuery(r.db, userQuery).Select(selectColumns(selectedFields))
+	addLimitAndSort(tx, userQuery)
+	tx = tx.Order("s.seq_id asc") // Makes the pages stable
+
+	experiments, err := scanRows(tx, selectedFields)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching sequencing experiments: %w", err)
+	}
+	return experiments, nil
+}
+
+func (r *MySQLRepository) CountSequencingExperiments(userQuery *types.Query) (int64, error) {
+	var count int64
+	err := buildSequencingExperimentsQuery(r.db, userQuery).Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("error counting sequencing experiments: %w", err)
+	}
+	return count, nil
+}
+
+// GetSequencingExperiment returns all the selectable fields of a sequencing experiment, or nil if it does not exist
+func (r *MySQLRepository) GetSequencingExperiment(seqId int) (*Row, error) {
+	fields := sliceutils.Filter(types.SequencingExperimentFields, func(field types.Field, index int, slice []types.Field) bool {
+		return field.CanBeSelected
+	})
+	tx := r.db.Table("sequencing_experiment s").Select(selectColumns(fields)).Where("s.seq_id = ?", seqId).Limit(1)
+	experiments, err := scanRows(tx, fields)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching sequencing experiment: %w", err)
+	}
+	if len(experiments) == 0 {
+		return nil, nil
+	}
+	return &experiments[0], nil
+}
+
+// buildSequencingExperimentsQuery returns the query on the sequencing experiments matching the filters of the user query
+func buildSequencingExperimentsQuery(db *gorm.DB, userQuery *types.Query) *gorm.DB {
+	tx := db.Table("sequencing_experiment s")
+	if userQuery != nil && userQuery.Filters != nil {
+		filters, params := userQuery.Filters.ToSQL()
+		tx = tx.Where(filters, params...)
+	}
+	return tx
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-poc/internal/types"
+	"go-poc/test/testutils"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestGetSequencingExperiments(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "catalog", func(t *testing.T, db *gorm.DB) {
+		repo := New(db)
+		query := types.Query{
+			Filters: &types.ComparisonNode{
+				Operator: "in",
+				Value:    "FM0001",
+				Field:    types.FamilyIdField,
+			},
+			SelectedFields: []types.Field{types.ExperimentSeqIdField, types.PatientIdField, types.AnalysisDateField},
+			SortedFields:   []types.SortField{{Field: types.AnalysisDateField, Order: "desc"}},
+		}
+		experiments, err := repo.GetSequencingExperiments(&query)
+		assert.NoError(t, err)
+		if assert.Len(t, experiments, 2) {
+			assert.EqualValues(t, 2, experiments[0].Get("seq_id"))
+			assert.Equal(t, "PA0002", experiments[0].Get("patient_id"))
+			assert.Equal(t, "2024-02-20", experiments[0].Get("analysis_date"))
+			assert.EqualValues(t, 1, experiments[1].Get("seq_id"))
+		}
+	})
+}
+
+func TestCountSequencingExperiments(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "catalog", func(t *testing.T, db *gorm.DB) {
+		repo := New(db)
+		query := types.Query{
+			Filters: &types.ComparisonNode{
+				Operator: "in",
+				Value:    "completed",
+				Field:    types.StatusField,
+			},
+		}
+		count, err := repo.CountSequencingExperiments(&query)
+		if assert.NoError(t, err) {
+			assert.EqualValues(t, 2, count)
+		}
+	})
+}
+
+func TestGetSequencingExperiment(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "catalog", func(t *testing.T, db *gorm.DB) {
+		repo := New(db)
+		experiment, err := repo.GetSequencingExperiment(3)
+		if assert.NoError(t, err) && assert.NotNil(t, experiment) {
+			assert.Equal(t, []string{"seq_id", "patient_id", "family_id", "sample_type", "analysis_date", "status"}, experiment.Columns)
+			assert.Equal(t, "saliva", experiment.Get("sample_type"))
+			assert.Equal(t, "in_progress", experiment.Get("status"))
+		}
+
+		experiment, err = repo.GetSequencingExperiment(42)
+		assert.NoError(t, err)
+		assert.Nil(t, experiment)
+	})
+}
+
+func TestGetPartUnknownExperiment(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "catalog", func(t *testing.T, db *gorm.DB) {
+		repo := New(db)
+		part, err := repo.GetPart(3)
+		if assert.NoError(t, err) {
+			assert.Equal(t, 2, part)
+		}
+
+		_, err = repo.GetPart(42)
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		_, err = repo.CountOccurrences(42, nil)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestGetPartCached(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "catalog", func(t *testing.T, db *gorm.DB) {
+		repo := New(db)
+		count, err := repo.WarmUpPartCache()
+		if assert.NoError(t, err) {
+			assert.Equal(t, 3, count)
+		}
+		part, err := repo.GetPart(3)
+		if assert.NoError(t, err) {
+			assert.Equal(t, 2, part)
+		}
+		_, err = repo.GetPart(42)
+		assert.ErrorIs(t, err, ErrNotFound)
+		_, err = repo.GetPart(42)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.Equal(t, PartCacheStats{Hits: 2, Misses: 1, Size: 4}, repo.PartCacheStats())
+	})
+}
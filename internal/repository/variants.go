@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/Goldziher/go-utils/sliceutils"
+	"go-poc/internal/types"
+	"gorm.io/gorm"
+)
+
+func (r *MySQLRepository) GetVariants(userQuery *types.Query) ([]Row, error) {
+	selectedFields := userQuery.SelectedFields
+	if len(selectedFields) == 0 {
+		selectedFields = []types.Field{types.VariantLocusIdField}
+	}
+	scannedFields := append(selectedFields[:len(selectedFields):len(selectedFields)], keysetFields(userQuery)...)
+	tx := buildVariantsQuery(r.db, userQuery).Select(selectColumns(scannedFields))
+	addLimitAndSort(tx, userQuery)
+
+	variants, err := scanRows(tx, scannedFields)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching variants: %w", err)
+	}
+	for i := range variants {
+		splitKeyset(&variants[i], len(selectedFields))
+	}
+	return variants, nil
+}
+
+func (r *MySQLRepository) CountVariants(userQuery *types.Query) (int64, error) {
+	var count int64
+	err := buildVariantsQuery(r.db, userQuery).Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("error counting variants: %w", err)
+	}
+	return count, nil
+}
+
+func (r *MySQLRepository) AggregateVariants(userQuery *types.Query, options types.TermsOptions) (TermsAggregation, error) {
+	return aggregateTerms(func() *gorm.DB {
+		return buildVariantsQuery(r.db, userQuery)
+	}, userQuery.SelectedFields[0], options)
+}
+
+// GetVariant returns all the selectable fields of a variant, or nil if the locus does not exist
+func (r *MySQLRepository) GetVariant(locusId int) (*Row, error) {
+	fields := sliceutils.Filter(types.VariantsFields, func(field types.Field, index int, slice []types.Field) bool {
+		return field.CanBeSelected
+	})
+	tx := r.db.Table("variants v").Select(selectColumns(fields)).Where("v.locus_id = ?", locusId).Limit(1)
+	variants, err := scanRows(tx, fields)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching variant: %w", err)
+	}
+	if len(variants) == 0 {
+		return nil, nil
+	}
+	return &variants[0], nil
+}
+
+// buildVariantsQuery returns the query on the variants matching the filters of the user query
+func buildVariantsQuery(db *gorm.DB, userQuery *types.Query) *gorm.DB {
+	tx := db.Table("variants v")
+	if userQuery != nil && userQuery.Filters != nil {
+		filters, params := userQuery.Filters.ToSQL()
+		tx = tx.Where(filters, params...)
+	}
+	return tx
+}
+
+func (r *MySQLRepository) GetVariantOccurrences(locusId int, userQuery *types.Query) ([]Row, error) {
+	selectedFields := userQuery.SelectedFields
+	if len(selectedFields) == 0 {
+		selectedFields = []types.Field{types.SeqIdField}
+	}
+	scannedFields := append(selectedFields[:len(selectedFields):len(selectedFields)], keysetFields(userQuery)...)
+	tx := buildVariantOccurrencesQuery(r.db, locusId, userQuery).Select(selectColumns(scannedFields))
+	addLimitAndSort(tx, userQuery)
+	tx = tx.Order("o.seq_id asc") // Makes the pages stable
+
+	occurrences, err := scanRows(tx, scannedFields)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching variant occurrences: %w", err)
+	}
+	for i := range occurrences {
+		splitKeyset(&occurrences[i], len(selectedFields))
+	}
+	return occurrences, nil
+}
+
+func (r *MySQLRepository) CountVariantOccurrences(locusId int, userQuery *types.Query) (int64, error) {
+	var count int64
+	err := buildVariantOccurrencesQuery(r.db, locusId, userQuery).Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("error counting variant occurrences: %w", err)
+	}
+	return count, nil
+}
+
+// buildVariantOccurrencesQuery returns the query on the occurrences of a locus in all the sequencing experiments,
+// joined with the experiments so that only the occurrences of known sequencing experiments are returned
+func buildVariantOccurrencesQuery(db *gorm.DB, locusId int, userQuery *types.Query) *gorm.DB {
+	tx := db.Table("occurrences o").
+		Joins("JOIN sequencing_experiment s ON s.part = o.part AND s.seq_id = o.seq_id").
+		Where("o.locus_id = ? and o.has_alt", locusId)
+	if userQuery != nil && userQuery.Filters != nil {
+		filters, params := userQuery.Filters.ToSQL()
+		tx = tx.Where(filters, params...)
+	}
+	return tx
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-poc/internal/types"
+	"go-poc/test/testutils"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestGetVariants(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "qc", func(t *testing.T, db *gorm.DB) {
+		repo := New(db)
+		query := types.Query{
+			SelectedFields: []types.Field{types.VariantLocusIdField, types.SymbolField, types.AfField},
+			Pagination:     &types.Pagination{Limit: 2},
+			SortedFields:   []types.SortField{{Field: types.SymbolField, Order: "desc"}},
+		}
+		variants, err := repo.GetVariants(&query)
+		assert.NoError(t, err)
+		if assert.Len(t, variants, 2) {
+			assert.EqualValues(t, 1002, variants[0].Get("locus_id"))
+			assert.Equal(t, "TTN", variants[0].Get("symbol"))
+			assert.Equal(t, "BRCA2", variants[1].Get("symbol"))
+			assert.Equal(t, []string{"locus_id", "symbol", "af"}, variants[1].Columns)
+		}
+	})
+}
+
+func TestCountVariants(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "qc", func(t *testing.T, db *gorm.DB) {
+		repo := New(db)
+		query := types.Query{
+			Filters: &types.ComparisonNode{
+				Operator: "prefix",
+				Value:    "BRCA",
+				Field:    types.SymbolField,
+			},
+			FilteredFields: []types.Field{types.SymbolField},
+		}
+		count, err := repo.CountVariants(&query)
+		if assert.NoError(t, err) {
+			assert.EqualValues(t, 2, count)
+		}
+	})
+}
+
+func TestAggregateVariants(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "qc", func(t *testing.T, db *gorm.DB) {
+		repo := New(db)
+		query := types.Query{
+			SelectedFields: []types.Field{types.SymbolField},
+		}
+		aggregation, err := repo.AggregateVariants(&query, types.TermsOptions{Size: 2, Order: types.OrderByKeyAsc})
+		if assert.NoError(t, err) {
+			assert.Equal(t, []Aggregation{{Bucket: "BRCA1", Count: 1}, {Bucket: "BRCA2", Count: 1}}, aggregation.Buckets)
+			assert.EqualValues(t, 1, aggregation.OtherCount)
+		}
+	})
+}
+
+func TestGetVariant(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "qc", func(t *testing.T, db *gorm.DB) {
+		repo := New(db)
+		variant, err := repo.GetVariant(1001)
+		if assert.NoError(t, err) && assert.NotNil(t, variant) {
+			assert.EqualValues(t, 1001, variant.Get("locus_id"))
+			assert.Equal(t, "BRCA2", variant.Get("symbol"))
+			assert.Equal(t, []interface{}{"synonymous_variant"}, variant.Get("consequence"))
+			assert.Equal(t, true, variant.Get("canonical"))
+		}
+
+		variant, err = repo.GetVariant(2000)
+		assert.NoError(t, err)
+		assert.Nil(t, variant)
+	})
+}
+
+func TestGetVariantOccurrences(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "aggregation", func(t *testing.T, db *gorm.DB) {
+		repo := New(db)
+		query := types.Query{
+			SelectedFields: []types.Field{types.SeqIdField, types.ZygosityField},
+			Pagination:     &types.Pagination{Limit: 10},
+		}
+		occurrences, err := repo.GetVariantOccurrences(1000, &query)
+		assert.NoError(t, err)
+		if assert.Len(t, occurrences, 2) {
+			assert.EqualValues(t, 1, occurrences[0].Get("seq_id"))
+			assert.EqualValues(t, 2, occurrences[1].Get("seq_id"))
+			assert.Equal(t, "HOM", occurrences[1].Get("zygosity"))
+		}
+
+		query.Pagination.Offset = 1
+		occurrences, err = repo.GetVariantOccurrences(1000, &query)
+		assert.NoError(t, err)
+		if assert.Len(t, occurrences, 1) {
+			assert.EqualValues(t, 2, occurrences[0].Get("seq_id"))
+		}
+	})
+}
+
+func TestGetVariantOccurrencesCursor(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "aggregation", func(t *testing.T, db *gorm.DB) {
+		repo := New(db)
+		query := types.Query{
+			SelectedFields: []types.Field{types.CarrierSeqIdField},
+			SortedFields:   []types.SortField{{Field: types.CarrierSeqIdField, Order: "asc"}, {Field: types.LocusIdField, Order: "asc"}},
+			Pagination:     &types.Pagination{Limit: 1, Cursor: &types.Cursor{}},
+		}
+		occurrences, err := repo.GetVariantOccurrences(1000, &query)
+		assert.NoError(t, err)
+		if assert.Len(t, occurrences, 2) {
+			assert.Equal(t, []string{"seq_id"}, occurrences[0].Columns)
+			assert.EqualValues(t, []interface{}{int64(1), int64(1000)}, occurrences[0].Keyset)
+		}
+
+		query.Pagination.Cursor = &types.Cursor{Values: occurrences[0].Keyset}
+		occurrences, err = repo.GetVariantOccurrences(1000, &query)
+		assert.NoError(t, err)
+		if assert.Len(t, occurrences, 1) {
+			assert.EqualValues(t, 2, occurrences[0].Get("seq_id"))
+		}
+	})
+}
+
+func TestCountVariantOccurrences(t *testing.T) {
+	testutils.ParallelTestWithDb(t, "aggregation", func(t *testing.T, db *gorm.DB) {
+		repo := New(db)
+		query := types.Query{
+			Filters: &types.ComparisonNode{
+				Operator: "in",
+				Value:    "LowQuality",
+				Field:    types.FilterField,
+			},
+		}
+		count, err := repo.CountVariantOccurrences(2000, &query)
+		if assert.NoError(t, err) {
+			assert.EqualValues(t, 1, count)
+		}
+		count, err = repo.CountVariantOccurrences(1000, &query)
+		if assert.NoError(t, err) {
+			assert.EqualValues(t, 0, count)
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"crypto/subtle"
+	"github.com/gin-gonic/gin"
+	"go-poc/internal/repository"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// AdminAuth rejects the requests which do not give the admin token as bearer token
+func AdminAuth(token string) gin.HandlerFunc {
+	expected := []byte("Bearer " + token)
+	return func(c *gin.Context) {
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), expected) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// PartCacheStatsHandler returns the hits, misses and size of the cache of the partitions of the sequencing experiments
+func PartCacheStatsHandler(repo repository.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, repo.PartCacheStats())
+	}
+}
+
+// PartCacheInvalidateHandler forgets the cached partition of the sequencing experiment of the path, or all the cached
+// partitions when the path has no seq_id
+func PartCacheInvalidateHandler(repo repository.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if param := c.Param("seq_id"); param != "" {
+			seqID, err := strconv.Atoi(param)
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+				return
+			}
+			repo.InvalidatePartCache(seqID)
+		} else {
+			repo.InvalidatePartCache()
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// WithResultCache serves the handler with a repository caching the results of the occurrence queries. The X-Cache header
+// of the response is HIT when all the results come from the cache, MISS otherwise.
+func WithResultCache(repo repository.Repository, cache *repository.ResultCache, handler func(repository.Repository) gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			mu     sync.Mutex
+			missed bool
+		)
+		cached := repository.NewCachedRepository(repo, cache, func(hit bool) {
+			mu.Lock()
+			defer mu.Unlock()
+			missed = missed || !hit
+			if missed {
+				c.Header("X-Cache", "MISS")
+			} else {
+				c.Header("X-Cache", "HIT")
+			}
+		})
+		handler(cached)(c)
+	}
+}
+
+// ResultCacheStatsHandler returns the hits, misses and size of the cache of the query results
+func ResultCacheStatsHandler(cache *repository.ResultCache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, cache.Stats())
+	}
+}
+
+// ResultCacheInvalidateHandler forgets the cached partition and results of the sequencing experiment of the path, e.g.
+// after its data is reloaded, or all the cached partitions and results when the path has no seq_id
+func ResultCacheInvalidateHandler(repo repository.Repository, cache *repository.ResultCache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if param := c.Param("seq_id"); param != "" {
+			seqID, err := strconv.Atoi(param)
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+				return
+			}
+			repo.InvalidatePartCache(seqID)
+			cache.InvalidateSeqId(seqID)
+		} else {
+			repo.InvalidatePartCache()
+			cache.Invalidate()
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"bytes"
+	"go-poc/internal/repository"
+	"go-poc/internal/types"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminAuth(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	admin := router.Group("/admin", AdminAuth("secret"))
+	admin.GET("/cache/parts", PartCacheStatsHandler(repo))
+
+	for _, authorization := range []string{"", "Bearer other", "secret"} {
+		req, _ := http.NewRequest("GET", "/admin/cache/parts", nil)
+		req.Header.Set("Authorization", authorization)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.JSONEq(t, `{"error": "unauthorized"}`, w.Body.String())
+	}
+
+	req, _ := http.NewRequest("GET", "/admin/cache/parts", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestPartCacheStatsHandler(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.GET("/admin/cache/parts", PartCacheStatsHandler(repo))
+
+	req, _ := http.NewRequest("GET", "/admin/cache/parts", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"hits": 5, "misses": 2, "size": 2}`, w.Body.String())
+}
+
+func TestPartCacheInvalidateHandler(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.DELETE("/admin/cache/parts", PartCacheInvalidateHandler(repo))
+	router.DELETE("/admin/cache/parts/:seq_id", PartCacheInvalidateHandler(repo))
+
+	req, _ := http.NewRequest("DELETE", "/admin/cache/parts/3", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	req, _ = http.NewRequest("DELETE", "/admin/cache/parts", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	assert.Equal(t, [][]int{{3}, nil}, repo.invalidations)
+}
+
+func TestPartCacheInvalidateHandlerInvalidSeqId(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.DELETE("/admin/cache/parts/:seq_id", PartCacheInvalidateHandler(repo))
+
+	req, _ := http.NewRequest("DELETE", "/admin/cache/parts/abc", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, repo.invalidations)
+}
+
+// countingRepository counts the occurrences counts reaching the repository
+type countingRepository struct {
+	MockRepository
+	calls int
+}
+
+func (m *countingRepository) CountOccurrences(seqId int, query *types.Query) (int64, error) {
+	m.calls++
+	return m.MockRepository.CountOccurrences(seqId, query)
+}
+
+func postCount(router *gin.Engine, seqId string, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", "/occurrences/"+seqId+"/count", bytes.NewBuffer([]byte(body)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestWithResultCache(t *testing.T) {
+	repo := &countingRepository{}
+	cache := repository.NewResultCache(10, time.Hour)
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/count", WithResultCache(repo, cache, OccurrencesCountHandler))
+
+	w := postCount(router, "1", `{"sqon": {"op": "in", "field": "zygosity", "value": ["HET", "HOM"]}}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+	assert.JSONEq(t, `{"count":15}`, w.Body.String())
+
+	w = postCount(router, "1", `{"sqon": {"op": "in", "field": "zygosity", "value": ["HOM", "HET"]}}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "HIT", w.Header().Get("X-Cache"))
+	assert.JSONEq(t, `{"count":15}`, w.Body.String())
+
+	w = postCount(router, "2", `{"sqon": {"op": "in", "field": "zygosity", "value": ["HOM", "HET"]}}`)
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+	assert.Equal(t, 2, repo.calls)
+}
+
+func TestResultCacheInvalidateHandler(t *testing.T) {
+	repo := &countingRepository{}
+	cache := repository.NewResultCache(10, time.Hour)
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/count", WithResultCache(repo, cache, OccurrencesCountHandler))
+	router.GET("/admin/cache/results", ResultCacheStatsHandler(cache))
+	router.DELETE("/admin/cache/results/:seq_id", ResultCacheInvalidateHandler(repo, cache))
+
+	postCount(router, "1", `{}`)
+	postCount(router, "1", `{}`)
+
+	req, _ := http.NewRequest("DELETE", "/admin/cache/results/1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = postCount(router, "1", `{}`)
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+	assert.Equal(t, 2, repo.calls)
+	assert.Equal(t, [][]int{{1}}, repo.invalidations)
+
+	req, _ = http.NewRequest("GET", "/admin/cache/results", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.JSONEq(t, `{"hits": 1, "misses": 2, "size": 1}`, w.Body.String())
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go-poc/internal/repository"
+	"go-poc/internal/types"
+	"net/http"
+)
+
+// CohortCountHandler counts the occurrences matching the SQON of each sequencing experiment of a cohort
+func CohortCountHandler(repo repository.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body types.CohortBody
+
+		// Bind JSON to the struct
+		if err := c.ShouldBindJSON(&body); err != nil {
+			// Return a 400 Bad Request if validation fails
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		query, err := types.BuildQuery(nil, body.SQON, &types.OccurrencesFields, nil, nil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		seqIDs, ok := cohortSeqIds(c, repo, &body)
+		if !ok {
+			return
+		}
+		counts, err := repo.CountCohortOccurrences(seqIDs, &query)
+		if err != nil {
+			writeRepositoryError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, counts)
+	}
+}
+
+// CohortCarriersHandler lists the loci of the occurrences matching the SQON in a cohort with their number of carriers
+func CohortCarriersHandler(repo repository.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body types.CohortBody
+
+		// Bind JSON to the struct
+		if err := c.ShouldBindJSON(&body); err != nil {
+			// Return a 400 Bad Request if validation fails
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if body.Limit < 0 || body.Offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit and offset must not be negative"})
+			return
+		}
+		p := types.Pagination{Limit: body.Limit, Offset: body.Offset}
+		if body.Limit == 0 {
+			p.Limit = DefaultLimit
+		}
+		query, err := types.BuildQuery(nil, body.SQON, &types.OccurrencesFields, &p, nil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		seqIDs, ok := cohortSeqIds(c, repo, &body)
+		if !ok {
+			return
+		}
+		carriers, err := repo.GetCohortCarriers(seqIDs, &query)
+		if err != nil {
+			writeRepositoryError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, carriers)
+	}
+}
+
+// cohortSeqIds returns the sequencing experiments of the body, given either as seq_ids or as a cohort_id.
+// It writes the error response and returns false if they cannot be resolved.
+func cohortSeqIds(c *gin.Context, repo repository.Repository, body *types.CohortBody) ([]int, bool) {
+	seqIDs, err := validateCohort(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if body.CohortId != nil {
+		if seqIDs, err = repo.GetCohort(*body.CohortId); err != nil {
+			writeRepositoryError(c, err)
+			return nil, false
+		}
+		if len(seqIDs) > repository.MaxCohortSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("cohort %s must contain at most %d sequencing experiments", *body.CohortId, repository.MaxCohortSize)})
+			return nil, false
+		}
+	}
+	return seqIDs, true
+}
+
+// validateCohort checks that the body gives either seq_ids or a cohort_id, and returns the seq_ids if given
+func validateCohort(body *types.CohortBody) ([]int, error) {
+	if body.CohortId != nil && len(body.SeqIds) > 0 {
+		return nil, errors.New("seq_ids and cohort_id cannot both be defined")
+	}
+	if body.CohortId == nil && len(body.SeqIds) == 0 {
+		return nil, errors.New("seq_ids or cohort_id must be defined")
+	}
+	if len(body.SeqIds) > repository.MaxCohortSize {
+		return nil, fmt.Errorf("seq_ids must contain at most %d elements", repository.MaxCohortSize)
+	}
+	return body.SeqIds, nil
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"bytes"
+	"go-poc/internal/repository"
+	"go-poc/internal/types"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCohortCountHandlerWithSeqIds(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/cohort/count", CohortCountHandler(repo))
+
+	body := `{
+			"seq_ids": [4, 5],
+			"sqon": {"op": "in", "field": "symbol", "value": ["TTN"]}
+		}`
+	req, _ := http.NewRequest("POST", "/cohort/count", bytes.NewBuffer([]byte(body)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"seq_id": 4, "count": 0}, {"seq_id": 5, "count": 1}]`, w.Body.String())
+	sql, params := repo.lastQuery.Filters.ToSQL()
+	assert.Equal(t, "v.symbol = ?", sql)
+	assert.Equal(t, []interface{}{"TTN"}, params)
+}
+
+func TestCohortCountHandlerWithCohortId(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/cohort/count", CohortCountHandler(repo))
+
+	req, _ := http.NewRequest("POST", "/cohort/count", bytes.NewBuffer([]byte(`{"cohort_id": "C1"}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"seq_id": 1, "count": 0}, {"seq_id": 2, "count": 1}, {"seq_id": 3, "count": 2}]`, w.Body.String())
+}
+
+func TestCohortCountHandlerUnknownCohort(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/cohort/count", CohortCountHandler(repo))
+
+	req, _ := http.NewRequest("POST", "/cohort/count", bytes.NewBuffer([]byte(`{"cohort_id": "C2"}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error": "not found"}`, w.Body.String())
+}
+
+// largeCohortRepository returns cohorts of more sequencing experiments than can be queried at once
+type largeCohortRepository struct {
+	MockRepository
+}
+
+func (m *largeCohortRepository) GetCohort(string) ([]int, error) {
+	seqIds := make([]int, repository.MaxCohortSize+1)
+	for i := range seqIds {
+		seqIds[i] = i + 1
+	}
+	return seqIds, nil
+}
+
+func TestCohortCountHandlerTooLargeCohort(t *testing.T) {
+	repo := &largeCohortRepository{}
+	router := gin.Default()
+	router.POST("/cohort/count", CohortCountHandler(repo))
+
+	req, _ := http.NewRequest("POST", "/cohort/count", bytes.NewBuffer([]byte(`{"cohort_id": "C1"}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "cohort C1 must contain at most 1000 sequencing experiments"}`, w.Body.String())
+}
+
+func TestCohortCountHandlerInvalidCohort(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/cohort/count", CohortCountHandler(repo))
+
+	req, _ := http.NewRequest("POST", "/cohort/count", bytes.NewBuffer([]byte(`{"seq_ids": [1], "cohort_id": "C1"}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "seq_ids and cohort_id cannot both be defined"}`, w.Body.String())
+
+	req, _ = http.NewRequest("POST", "/cohort/count", bytes.NewBuffer([]byte(`{}`)))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "seq_ids or cohort_id must be defined"}`, w.Body.String())
+}
+
+func TestCohortCarriersHandler(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/cohort/carriers", CohortCarriersHandler(repo))
+
+	body := `{
+			"seq_ids": [1, 2],
+			"sqon": {"op": "in", "field": "vep_impact", "value": ["HIGH"]},
+			"limit": 5,
+			"offset": 10
+		}`
+	req, _ := http.NewRequest("POST", "/cohort/carriers", bytes.NewBuffer([]byte(body)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"locus_id": 1000, "carriers": 2, "het": 1, "hom": 1}]`, w.Body.String())
+	assert.Equal(t, &types.Pagination{Limit: 5, Offset: 10}, repo.lastQuery.Pagination)
+}
+
+func TestCohortCarriersHandlerNegativeOffset(t *testing.T) {
+	router := gin.Default()
+	router.POST("/cohort/carriers", CohortCarriersHandler(&MockRepository{}))
+
+	req, _ := http.NewRequest("POST", "/cohort/carriers", bytes.NewBuffer([]byte(`{"seq_ids": [1, 2], "offset": -1}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "limit and offset must not be negative"}`, w.Body.String())
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+	"go-poc/internal/repository"
+	"go-poc/internal/types"
+	"net/http"
+	"strconv"
+)
+
+// DefaultCompoundHetFields are the fields returned for each variant of a candidate gene when none is selected
+var DefaultCompoundHetFields = []string{"locus_id", "hgvsg", "ad_ratio"}
+
+// CompoundHetHandler lists the genes of a sequencing experiment having pairs of heterozygous occurrences matching the SQON,
+// the pairs being phased with the parents when the sequencing experiment is a proband
+func CompoundHetHandler(repo repository.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			body  types.CompoundHetBody
+			query types.Query
+		)
+
+		// Bind JSON to the struct
+		if err := c.ShouldBindJSON(&body); err != nil {
+			// Return a 400 Bad Request if validation fails
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if body.Limit < 0 || body.Offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit and offset must not be negative"})
+			return
+		}
+		if len(body.SelectedFields) == 0 {
+			body.SelectedFields = DefaultCompoundHetFields
+		}
+		p := types.Pagination{Limit: body.Limit, Offset: body.Offset}
+		if body.Limit == 0 {
+			p.Limit = DefaultLimit
+		}
+		query, err := types.BuildQuery(body.SelectedFields, body.SQON, &types.OccurrencesFields, &p, nil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		seqID, err := strconv.Atoi(c.Param("seq_id"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		genes, total, err := repo.GetCompoundHets(seqID, &query)
+		if err != nil {
+			writeRepositoryError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, types.CompoundHetResponse{
+			Data:    genes,
+			Total:   total,
+			Limit:   min(p.Limit, repository.MaxLimit),
+			Offset:  p.Offset,
+			HasMore: int64(p.Offset+len(genes)) < total,
+		})
+	}
+}
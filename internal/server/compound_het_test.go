@@ -0,0 +1,99 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"go-poc/internal/repository"
+	"go-poc/internal/types"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompoundHetHandler(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/compound-het", CompoundHetHandler(repo))
+
+	body := `{
+			"selected_fields": ["locus_id"],
+			"sqon": {"op": "in", "field": "vep_impact", "value": ["HIGH", "MODERATE"]},
+			"limit": 1
+		}`
+	req, _ := http.NewRequest("POST", "/occurrences/1/compound-het", bytes.NewBuffer([]byte(body)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	expected := `{
+			"data": [{
+				"symbol": "BRCA1",
+				"phased": true,
+				"variants": [{"locus_id": 1000, "origin": "maternal"}, {"locus_id": 1001, "origin": "paternal"}],
+				"pairs": [[1000, 1001]],
+				"pair_count": 1
+			}],
+			"total": 3,
+			"limit": 1,
+			"offset": 0,
+			"has_more": true
+		}`
+	assert.JSONEq(t, expected, w.Body.String())
+	assert.Equal(t, []types.Field{types.LocusIdField}, repo.lastQuery.SelectedFields)
+	assert.Equal(t, &types.Pagination{Limit: 1}, repo.lastQuery.Pagination)
+}
+
+func TestCompoundHetHandlerDefaultFields(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/compound-het", CompoundHetHandler(repo))
+
+	req, _ := http.NewRequest("POST", "/occurrences/1/compound-het", bytes.NewBuffer([]byte(`{}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []types.Field{types.LocusIdField, types.HgvsgField, types.AdRatioField}, repo.lastQuery.SelectedFields)
+	assert.Equal(t, &types.Pagination{Limit: DefaultLimit}, repo.lastQuery.Pagination)
+}
+
+func TestCompoundHetHandlerNegativePagination(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/compound-het", CompoundHetHandler(repo))
+
+	for _, body := range []string{`{"limit": -1}`, `{"offset": -10}`} {
+		req, _ := http.NewRequest("POST", "/occurrences/1/compound-het", bytes.NewBuffer([]byte(body)))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error": "limit and offset must not be negative"}`, w.Body.String())
+	}
+	assert.Nil(t, repo.lastQuery)
+}
+
+// tooManyOccurrencesRepository fails as if the sequencing experiment had too many heterozygous occurrences
+type tooManyOccurrencesRepository struct {
+	MockRepository
+}
+
+func (m *tooManyOccurrencesRepository) GetCompoundHets(int, *types.Query) ([]types.CompoundHetGene, int64, error) {
+	return nil, 0, fmt.Errorf("more than %d heterozygous occurrences: %w", repository.MaxCompoundHetOccurrences, repository.ErrTooManyOccurrences)
+}
+
+func TestCompoundHetHandlerTooManyOccurrences(t *testing.T) {
+	repo := &tooManyOccurrencesRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/compound-het", CompoundHetHandler(repo))
+
+	req, _ := http.NewRequest("POST", "/occurrences/1/compound-het", bytes.NewBuffer([]byte(`{}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "too many occurrences, refine the sqon"}`, w.Body.String())
+}
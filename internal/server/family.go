@@ -0,0 +1,119 @@
+package server
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go-poc/internal/repository"
+	"go-poc/internal/types"
+	"net/http"
+	"strconv"
+)
+
+func FamilyHandler(repo repository.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		seqID, err := strconv.Atoi(c.Param("seq_id"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		family, err := repo.GetFamily(seqID)
+		if err != nil {
+			writeRepositoryError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, family)
+	}
+}
+
+// FamilyOccurrencesListHandler lists the occurrences of a proband, the fields of the occurrences of its parents at the same locus
+// being selectable and filterable along with the inheritance mode of each occurrence
+func FamilyOccurrencesListHandler(repo repository.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			body  types.FamilyListBody
+			query types.Query
+		)
+
+		// Bind JSON to the struct
+		if err := c.ShouldBindJSON(&body); err != nil {
+			// Return a 400 Bad Request if validation fails
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		fields, err := types.FamilyFields(body.Inheritance)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		p, err := listPagination(&body.ListBody)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		query, err = types.BuildQuery(body.SelectedFields, body.SQON, &fields, &p, body.Sort)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		seqID, err := strconv.Atoi(c.Param("seq_id"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		writeList(c, body.Envelope, &query,
+			func(query *types.Query) ([]types.Row, error) { return repo.GetFamilyOccurrences(seqID, query) },
+			func(query *types.Query) (int64, error) { return repo.CountFamilyOccurrences(seqID, query) },
+		)
+	}
+}
+
+// FamilyOccurrencesAggregateHandler counts the occurrences of a proband by value of a family field, e.g. by inheritance mode
+func FamilyOccurrencesAggregateHandler(repo repository.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			body  types.FamilyAggregationBody
+			query types.Query
+		)
+
+		// Bind JSON to the struct
+		if err := c.ShouldBindJSON(&body); err != nil {
+			// Return a 400 Bad Request if validation fails
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		fields, err := types.FamilyFields(body.Inheritance)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		selected := []string{body.Field}
+		query, err = types.BuildQuery(selected, body.SQON, &fields, nil, nil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if len(query.SelectedFields) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unauthorized or unknown field: %s", body.Field)})
+			return
+		}
+		seqID, err := strconv.Atoi(c.Param("seq_id"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		options := types.TermsOptions{Size: body.Size, Order: body.Order, Missing: body.Missing}
+		if err := validateTermsOptions(options); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if body.ExcludeSelf {
+			query = query.WithoutFiltersOn(query.SelectedFields[0])
+		}
+		aggregation, err := repo.AggregateFamilyOccurrences(seqID, &query, options)
+		if err != nil {
+			writeRepositoryError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, aggregation)
+	}
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"bytes"
+	"go-poc/internal/types"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFamilyHandler(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.GET("/sequencing/:seq_id/family", FamilyHandler(repo))
+
+	req, _ := http.NewRequest("GET", "/sequencing/1/family", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"proband_seq_id": 1, "mother_seq_id": 2, "father_seq_id": null}`, w.Body.String())
+}
+
+func TestFamilyHandlerNotFound(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.GET("/sequencing/:seq_id/family", FamilyHandler(repo))
+
+	req, _ := http.NewRequest("GET", "/sequencing/2/family", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error": "not found"}`, w.Body.String())
+}
+
+func TestFamilyOccurrencesListHandler(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/family/list", FamilyOccurrencesListHandler(repo))
+
+	body := `{
+			"selected_fields": ["locus_id", "zygosity", "mother_zygosity", "father_zygosity"],
+			"sqon": {"op": "in", "field": "mother_zygosity", "value": ["WT"]}
+		}`
+	req, _ := http.NewRequest("POST", "/occurrences/1/family/list", bytes.NewBuffer([]byte(body)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"locus_id": 1000, "zygosity": "HET", "mother_zygosity": "WT", "father_zygosity": null}]`, w.Body.String())
+	assert.Equal(t, []types.Field{types.LocusIdField, types.ZygosityField, types.MotherZygosityField, types.FatherZygosityField}, repo.lastQuery.SelectedFields)
+	sql, params := repo.lastQuery.Filters.ToSQL()
+	assert.Equal(t, "mo.zygosity = ?", sql)
+	assert.Equal(t, []interface{}{"WT"}, params)
+}
+
+func TestFamilyOccurrencesListHandlerInvalidParentValue(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/family/list", FamilyOccurrencesListHandler(repo))
+
+	body := `{"sqon": {"op": "in", "field": "father_zygosity", "value": ["REF"]}}`
+	req, _ := http.NewRequest("POST", "/occurrences/1/family/list", bytes.NewBuffer([]byte(body)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "invalid value REF for field father_zygosity with operation in: expected one of [HOM, HET, WT, UNK]"}`, w.Body.String())
+}
+
+func TestFamilyOccurrencesListHandlerInheritanceFilter(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/family/list", FamilyOccurrencesListHandler(repo))
+
+	body := `{
+			"selected_fields": ["locus_id", "inheritance"],
+			"sqon": {"op": "in", "field": "inheritance", "value": ["de_novo"]},
+			"inheritance": {"min_gq": 30, "min_dp": 15}
+		}`
+	req, _ := http.NewRequest("POST", "/occurrences/1/family/list", bytes.NewBuffer([]byte(body)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	minGq, minDp := 30, 15
+	inheritance, _ := types.InheritanceField(types.InheritanceThresholds{MinGq: &minGq, MinDp: &minDp})
+	assert.Equal(t, []types.Field{types.LocusIdField, inheritance}, repo.lastQuery.SelectedFields)
+	assert.Contains(t, inheritance.Expression, "mo.gq >= 30 AND mo.dp >= 15")
+	sql, params := repo.lastQuery.Filters.ToSQL()
+	assert.Equal(t, "("+inheritance.Expression+") = ?", sql)
+	assert.Equal(t, []interface{}{"de_novo"}, params)
+}
+
+func TestFamilyOccurrencesListHandlerInvalidThreshold(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/family/list", FamilyOccurrencesListHandler(repo))
+
+	body := `{"selected_fields": ["locus_id"], "inheritance": {"min_gq": -1}}`
+	req, _ := http.NewRequest("POST", "/occurrences/1/family/list", bytes.NewBuffer([]byte(body)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "invalid min_gq -1: must not be negative"}`, w.Body.String())
+}
+
+func TestFamilyOccurrencesAggregateHandler(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/family/aggregate", FamilyOccurrencesAggregateHandler(repo))
+
+	body := `{
+			"field": "inheritance",
+			"sqon": {"op": "in", "field": "inheritance", "value": ["de_novo"]},
+			"exclude_self": true
+		}`
+	req, _ := http.NewRequest("POST", "/occurrences/1/family/aggregate", bytes.NewBuffer([]byte(body)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"buckets": [{"key": "de_novo", "count": 2}], "other_count": 0}`, w.Body.String())
+	inheritance, _ := types.InheritanceField(types.InheritanceThresholds{})
+	assert.Equal(t, []types.Field{inheritance}, repo.lastQuery.SelectedFields)
+	assert.Nil(t, repo.lastQuery.Filters)
+}
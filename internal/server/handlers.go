@@ -1,13 +1,62 @@
 package server
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"go-poc/internal/repository"
 	"go-poc/internal/types"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync"
 )
 
+const (
+	DefaultLimit        = 10
+	MaxFacets           = 20 // Maximum number of facets computed in a single request
+	MaxConcurrentFacets = 4  // Maximum number of facet queries running at the same time for a request
+	MaxStatsFields      = 20 // Maximum number of fields in a statistics request
+	MaxPercentiles      = 10 // Maximum number of percentiles computed per field
+)
+
+// DefaultPercentiles are the percentiles computed when none is requested
+var DefaultPercentiles = []float64{0.25, 0.5, 0.75}
+
+// nextCursor returns the rows of the page and the cursor of the following page, or nil if the rows are the last page.
+// With keyset pagination, the repository fetches one row more than the limit when there is a next page.
+func nextCursor(rows []types.Row, p *types.Pagination) ([]types.Row, *string, error) {
+	limit := min(p.Limit, repository.MaxLimit)
+	if len(rows) <= limit {
+		return rows, nil, nil
+	}
+	rows = rows[:limit]
+	cursor, err := types.EncodeCursor(rows[len(rows)-1].Keyset)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rows, &cursor, nil
+}
+
+// writeRepositoryError writes the response of a repository error, not found if the sequencing experiment does not exist
+// and bad request if the query matches too many occurrences
+func writeRepositoryError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+	if errors.Is(err, repository.ErrTooManyOccurrences) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "too many occurrences, refine the sqon"})
+		return
+	}
+	if errors.Is(err, repository.ErrTooManyBuckets) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("more than %d buckets, increase the interval", repository.MaxHistogramBuckets)})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+}
+
 func StatusHandler(repo repository.Repository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		status := repo.CheckDatabaseConnection()
@@ -30,15 +79,12 @@ func OccurrencesListHandler(repo repository.Repository) gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		var p types.Pagination
-		if body.Limit != 0 && body.Offset != 0 {
-			p = types.Pagination{Limit: body.Limit, Offset: body.Offset}
-		} else if body.Limit != 0 {
-			p = types.Pagination{Limit: body.Limit, Offset: 0}
-		} else {
-			p = types.Pagination{Limit: 10, Offset: 0}
+		p, err := listPagination(&body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
-		query, err := types.BuildQuery(body.SelectedFields, body.SQON, &types.OccurrencesFields, &p, body.Sort)
+		query, err = types.BuildQuery(body.SelectedFields, body.SQON, &types.OccurrencesFields, &p, body.Sort)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
@@ -48,14 +94,135 @@ func OccurrencesListHandler(repo repository.Repository) gin.HandlerFunc {
 			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 			return
 		}
-		occurrences, err := repo.GetOccurrences(seqID, &query)
-		if err != nil {
+		writeList(c, body.Envelope, &query,
+			func(query *types.Query) ([]types.Row, error) { return repo.GetOccurrences(seqID, query) },
+			func(query *types.Query) (int64, error) { return repo.CountOccurrences(seqID, query) },
+		)
+	}
+}
+
+// listPagination returns the pagination requested in the body of a list, or an error if the limit or the offset is negative
+func listPagination(body *types.ListBody) (types.Pagination, error) {
+	if body.Limit < 0 || body.Offset < 0 {
+		return types.Pagination{}, errors.New("limit and offset must not be negative")
+	}
+	p := types.Pagination{Limit: body.Limit, Offset: body.Offset}
+	if body.Limit == 0 {
+		p.Limit = DefaultLimit
+	}
+	if body.Cursor != nil {
+		p.Cursor = &types.Cursor{Token: *body.Cursor}
+	}
+	return p, nil
+}
+
+// listBodyFromQueryString reads the parameters of a list from the query string:
+// comma separated selected_fields, JSON encoded sqon, limit, offset and envelope
+func listBodyFromQueryString(c *gin.Context) (types.ListBody, error) {
+	var body types.ListBody
+	for _, selected := range c.QueryArray("selected_fields") {
+		body.SelectedFields = append(body.SelectedFields, strings.Split(selected, ",")...)
+	}
+	if sqon := c.Query("sqon"); sqon != "" {
+		body.SQON = &types.SQON{}
+		if err := json.Unmarshal([]byte(sqon), body.SQON); err != nil {
+			return body, fmt.Errorf("invalid sqon: %w", err)
+		}
+	}
+	var err error
+	if limit := c.Query("limit"); limit != "" {
+		if body.Limit, err = strconv.Atoi(limit); err != nil || body.Limit < 0 {
+			return body, fmt.Errorf("invalid limit: %s", limit)
+		}
+	}
+	if offset := c.Query("offset"); offset != "" {
+		if body.Offset, err = strconv.Atoi(offset); err != nil || body.Offset < 0 {
+			return body, fmt.Errorf("invalid offset: %s", offset)
+		}
+	}
+	body.Envelope = c.Query("envelope") == "true"
+	return body, nil
+}
+
+// writeList writes the page of rows matching the query, wrapped with the total count and the pagination metadata if envelope is set
+func writeList(c *gin.Context, envelope bool, query *types.Query, list func(*types.Query) ([]types.Row, error), count func(*types.Query) (int64, error)) {
+	var (
+		rows  []types.Row
+		total int64
+		err   error
+	)
+	if envelope {
+		rows, total, err = listAndCount(query, list, count)
+	} else {
+		rows, err = list(query)
+	}
+	if err != nil {
+		writeRepositoryError(c, err)
+		return
+	}
+
+	p := query.Pagination
+	var next *string
+	if p.Cursor != nil {
+		if rows, next, err = nextCursor(rows, p); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 			return
 		}
+	}
+
+	if envelope {
+		c.JSON(http.StatusOK, newListResponse(rows, total, query, next))
+	} else if p.Cursor != nil {
+		c.JSON(http.StatusOK, gin.H{"data": rows, "next_cursor": next})
+	} else {
+		c.JSON(http.StatusOK, rows)
+	}
+}
+
+// listAndCount fetches a page of rows and counts all the rows matching the query concurrently
+func listAndCount(query *types.Query, list func(*types.Query) ([]types.Row, error), count func(*types.Query) (int64, error)) ([]types.Row, int64, error) {
+	var (
+		wg                sync.WaitGroup
+		rows              []types.Row
+		total             int64
+		listErr, countErr error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		rows, listErr = list(query)
+	}()
+	go func() {
+		defer wg.Done()
+		total, countErr = count(query)
+	}()
+	wg.Wait()
+	if listErr != nil {
+		return nil, 0, listErr
+	}
+	return rows, total, countErr
+}
 
-		c.JSON(http.StatusOK, occurrences)
+// newListResponse wraps a page of rows with the pagination metadata
+func newListResponse(rows []types.Row, total int64, query *types.Query, next *string) types.ListResponse {
+	p := query.Pagination
+	response := types.ListResponse{
+		Data:       rows,
+		Total:      total,
+		Limit:      min(p.Limit, repository.MaxLimit),
+		NextCursor: next,
+		Sort:       []types.SortBody{},
 	}
+	for _, sort := range query.SortedFields {
+		response.Sort = append(response.Sort, types.SortBody{Field: sort.Field.GetAlias(), Order: sort.Order})
+	}
+	if p.Cursor != nil {
+		response.HasMore = next != nil
+	} else {
+		response.Offset = p.Offset
+		response.HasMore = int64(p.Offset+len(rows)) < total
+	}
+	return response
 }
 
 func OccurrencesCountHandler(repo repository.Repository) gin.HandlerFunc {
@@ -83,7 +250,7 @@ func OccurrencesCountHandler(repo repository.Repository) gin.HandlerFunc {
 		}
 		count, err := repo.CountOccurrences(seqID, &query)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			writeRepositoryError(c, err)
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{"count": count})
@@ -109,16 +276,269 @@ func OccurrencesAggregateHandler(repo repository.Repository) gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		if len(query.SelectedFields) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unauthorized or unknown field: %s", body.Field)})
+			return
+		}
 		seqID, err := strconv.Atoi(c.Param("seq_id"))
 		if err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 			return
 		}
-		aggregation, err := repo.AggregateOccurrences(seqID, &query)
+		options := types.TermsOptions{Size: body.Size, Order: body.Order, Missing: body.Missing}
+		if err := validateTermsOptions(options); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if body.ExcludeSelf {
+			query = query.WithoutFiltersOn(query.SelectedFields[0])
+		}
+		aggregation, err := repo.AggregateOccurrences(seqID, &query, options)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			writeRepositoryError(c, err)
 			return
 		}
 		c.JSON(http.StatusOK, aggregation)
 	}
 }
+
+// validateTermsOptions checks the options of a terms aggregation requested by the client
+func validateTermsOptions(options types.TermsOptions) error {
+	switch options.Order {
+	case "", types.OrderByCountDesc, types.OrderByCountAsc, types.OrderByKeyAsc, types.OrderByKeyDesc:
+	default:
+		return fmt.Errorf("invalid order: %s", options.Order)
+	}
+	if options.Size < 0 || options.Size > repository.MaxAggregationSize {
+		return fmt.Errorf("size must be between 0 and %d", repository.MaxAggregationSize)
+	}
+	return nil
+}
+
+func OccurrencesFacetsHandler(repo repository.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			body  types.FacetsBody
+			query types.Query
+		)
+
+		// Bind JSON to the struct
+		if err := c.ShouldBindJSON(&body); err != nil {
+			// Return a 400 Bad Request if validation fails
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if len(body.Fields) == 0 || len(body.Fields) > MaxFacets {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("fields must contain between 1 and %d fields", MaxFacets)})
+			return
+		}
+		query, err := types.BuildQuery(nil, body.SQON, &types.OccurrencesFields, nil, nil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		facets := make([]types.Field, len(body.Fields))
+		for i, name := range body.Fields {
+			field := types.FindByName(&types.OccurrencesFields, name)
+			if field == nil || !field.CanBeSelected {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unauthorized or unknown field: %s", name)})
+				return
+			}
+			facets[i] = *field
+		}
+		seqID, err := strconv.Atoi(c.Param("seq_id"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		options := types.TermsOptions{Size: body.Size, Order: body.Order, Missing: body.Missing}
+		if err := validateTermsOptions(options); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		aggregations, err := aggregateFacets(repo, seqID, &query, facets, options, body.ExcludeSelf)
+		if err != nil {
+			writeRepositoryError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, aggregations)
+	}
+}
+
+// aggregateFacets computes a terms aggregation for each facet, running at most MaxConcurrentFacets queries at the same time.
+// When excludeSelf is set, each facet ignores the top-level filters on its own field.
+func aggregateFacets(repo repository.Repository, seqID int, query *types.Query, facets []types.Field, options types.TermsOptions, excludeSelf bool) (map[string]types.TermsAggregation, error) {
+	var (
+		wg      sync.WaitGroup
+		indexes = make(chan int)
+		results = make([]types.TermsAggregation, len(facets))
+		errs    = make([]error, len(facets))
+	)
+	for w := 0; w < min(MaxConcurrentFacets, len(facets)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				facetQuery := *query
+				if excludeSelf {
+					facetQuery = query.WithoutFiltersOn(facets[i])
+				}
+				facetQuery.SelectedFields = []types.Field{facets[i]}
+				results[i], errs[i] = repo.AggregateOccurrences(seqID, &facetQuery, options)
+			}
+		}()
+	}
+	for i := range facets {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	aggregations := make(map[string]types.TermsAggregation, len(facets))
+	for i, facet := range facets {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		aggregations[facet.GetAlias()] = results[i]
+	}
+	return aggregations, nil
+}
+
+func OccurrencesHistogramHandler(repo repository.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			body  types.HistogramBody
+			query types.Query
+		)
+
+		// Bind JSON to the struct
+		if err := c.ShouldBindJSON(&body); err != nil {
+			// Return a 400 Bad Request if validation fails
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		selected := []string{body.Field}
+		query, err := types.BuildQuery(selected, body.SQON, &types.OccurrencesFields, nil, nil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if len(query.SelectedFields) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unauthorized or unknown field: %s", body.Field)})
+			return
+		}
+		if !query.SelectedFields[0].IsNumeric() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("histogram is not supported for field: %s", body.Field)})
+			return
+		}
+		seqID, err := strconv.Atoi(c.Param("seq_id"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		options, err := histogramOptions(&body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if body.ExcludeSelf {
+			query = query.WithoutFiltersOn(query.SelectedFields[0])
+		}
+		histogram, err := repo.HistogramOccurrences(seqID, &query, options)
+		if err != nil {
+			writeRepositoryError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, histogram)
+	}
+}
+
+// histogramOptions returns the options of the histogram requested in the body, the interval defaulting to 1 on a log scale
+func histogramOptions(body *types.HistogramBody) (types.HistogramOptions, error) {
+	if len(body.Ranges) > 0 {
+		if body.Interval != 0 || body.LogScale {
+			return types.HistogramOptions{}, fmt.Errorf("ranges cannot be combined with interval or log_scale")
+		}
+		if len(body.Ranges) > repository.MaxHistogramBuckets {
+			return types.HistogramOptions{}, fmt.Errorf("ranges must contain at most %d ranges", repository.MaxHistogramBuckets)
+		}
+		for _, r := range body.Ranges {
+			if r.From == nil && r.To == nil {
+				return types.HistogramOptions{}, fmt.Errorf("a range must have a from or a to bound")
+			}
+			if r.From != nil && r.To != nil && *r.From >= *r.To {
+				return types.HistogramOptions{}, fmt.Errorf("invalid range [%v, %v): from must be lower than to", *r.From, *r.To)
+			}
+		}
+		return types.HistogramOptions{Ranges: body.Ranges}, nil
+	}
+	options := types.HistogramOptions{Interval: body.Interval, LogScale: body.LogScale}
+	if options.LogScale && options.Interval == 0 {
+		options.Interval = 1
+	}
+	if options.Interval <= 0 {
+		return types.HistogramOptions{}, fmt.Errorf("interval must be positive")
+	}
+	return options, nil
+}
+
+func OccurrencesStatsHandler(repo repository.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			body  types.StatsBody
+			query types.Query
+		)
+
+		// Bind JSON to the struct
+		if err := c.ShouldBindJSON(&body); err != nil {
+			// Return a 400 Bad Request if validation fails
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if len(body.Fields) == 0 || len(body.Fields) > MaxStatsFields {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("fields must contain between 1 and %d fields", MaxStatsFields)})
+			return
+		}
+		for _, name := range body.Fields {
+			field := types.FindByName(&types.OccurrencesFields, name)
+			if field == nil || !field.CanBeSelected {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unauthorized or unknown field: %s", name)})
+				return
+			}
+			if !field.IsNumeric() {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("stats are not supported for field: %s", name)})
+				return
+			}
+		}
+		percentiles := body.Percentiles
+		if percentiles == nil {
+			percentiles = DefaultPercentiles
+		}
+		if len(percentiles) > MaxPercentiles {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("percentiles must contain at most %d values", MaxPercentiles)})
+			return
+		}
+		for _, p := range percentiles {
+			if p < 0 || p > 1 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid percentile %v: expected a value between 0 and 1", p)})
+				return
+			}
+		}
+		query, err := types.BuildQuery(body.Fields, body.SQON, &types.OccurrencesFields, nil, nil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		seqID, err := strconv.Atoi(c.Param("seq_id"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		stats, err := repo.StatsOccurrences(seqID, &query, percentiles)
+		if err != nil {
+			writeRepositoryError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, stats)
+	}
+}
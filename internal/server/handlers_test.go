@@ -2,33 +2,37 @@ package server
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"go-poc/internal/repository"
 	"go-poc/internal/types"
 	"net/http"
 	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
 
-type MockRepository struct{}
+type MockRepository struct {
+	lastQuery     *types.Query
+	invalidations [][]int // Sequencing experiments of each invalidation of the partition cache
+}
 
 func (m *MockRepository) CheckDatabaseConnection() string {
 	return "up"
 }
 
-func (m *MockRepository) GetOccurrences(int, *types.Query) ([]types.Occurrence, error) {
-	return []types.Occurrence{
+func (m *MockRepository) GetOccurrences(_ int, query *types.Query) ([]types.Row, error) {
+	m.lastQuery = query
+	return []types.Row{
 		{
-			SeqId:        1,
-			LocusId:      1000,
-			Filter:       "PASS",
-			Zygosity:     "HET",
-			Pf:           0.99,
-			Af:           0.01,
-			Hgvsg:        "hgvsg1",
-			AdRatio:      1.0,
-			VariantClass: "class1",
+			Columns: []string{"seq_id", "locus_id", "filter", "zygosity", "pf", "af", "hgvsg", "ad_ratio", "variant_class"},
+			Values:  []interface{}{1, 1000, "PASS", "HET", 0.99, 0.01, "hgvsg1", 1.0, "class1"},
+			Keyset:  []interface{}{1000},
 		},
 	}, nil
 }
@@ -37,12 +41,180 @@ func (m *MockRepository) CountOccurrences(int, *types.Query) (int64, error) {
 	return 15, nil
 }
 
-func (m *MockRepository) AggregateOccurrences(int, *types.Query) ([]types.Aggregation, error) {
-	return []types.Aggregation{
+func (m *MockRepository) AggregateOccurrences(_ int, _ *types.Query, options types.TermsOptions) (types.TermsAggregation, error) {
+	aggregation := types.TermsAggregation{
+		Buckets: []types.Aggregation{
 			{Bucket: "HET", Count: 2},
 			{Bucket: "HOM", Count: 1},
 		},
-		nil
+		OtherCount: 3,
+	}
+	if options.Missing {
+		missing := int64(4)
+		aggregation.Missing = &missing
+	}
+	return aggregation, nil
+}
+
+func (m *MockRepository) HistogramOccurrences(_ int, query *types.Query, options types.HistogramOptions) (types.Histogram, error) {
+	m.lastQuery = query
+	if len(options.Ranges) > 0 {
+		histogram := types.Histogram{}
+		for _, r := range options.Ranges {
+			histogram.Buckets = append(histogram.Buckets, types.HistogramBucket{Range: r, Count: 1})
+		}
+		return histogram, nil
+	}
+	from, to := 0.0, options.Interval
+	return types.Histogram{Buckets: []types.HistogramBucket{{Range: types.Range{From: &from, To: &to}, Count: 5}}}, nil
+}
+
+func (m *MockRepository) StatsOccurrences(_ int, query *types.Query, percentiles []float64) (map[string]types.FieldStats, error) {
+	m.lastQuery = query
+	stats := map[string]types.FieldStats{}
+	for _, field := range query.SelectedFields {
+		min, max := 0.0, 1.0
+		s := types.FieldStats{Count: 2, Min: &min, Max: &max, Mean: &max, Stddev: &min, Percentiles: []types.Percentile{}}
+		for _, p := range percentiles {
+			s.Percentiles = append(s.Percentiles, types.Percentile{Percentile: p, Value: &max})
+		}
+		stats[field.GetAlias()] = s
+	}
+	return stats, nil
+}
+
+func (m *MockRepository) GetVariants(query *types.Query) ([]types.Row, error) {
+	m.lastQuery = query
+	return []types.Row{
+		{
+			Columns: []string{"locus_id", "symbol", "pf"},
+			Values:  []interface{}{1000, "BRCA1", 0.5},
+			Keyset:  []interface{}{1000},
+		},
+	}, nil
+}
+
+func (m *MockRepository) CountVariants(*types.Query) (int64, error) {
+	return 8, nil
+}
+
+func (m *MockRepository) AggregateVariants(query *types.Query, options types.TermsOptions) (types.TermsAggregation, error) {
+	m.lastQuery = query
+	return types.TermsAggregation{Buckets: []types.Aggregation{{Bucket: "BRCA1", Count: 3}}, OtherCount: 1}, nil
+}
+
+func (m *MockRepository) GetVariant(locusId int) (*types.Row, error) {
+	if locusId != 1000 {
+		return nil, nil
+	}
+	return &types.Row{Columns: []string{"locus_id", "symbol"}, Values: []interface{}{1000, "BRCA1"}}, nil
+}
+
+func (m *MockRepository) GetVariantOccurrences(_ int, query *types.Query) ([]types.Row, error) {
+	m.lastQuery = query
+	return []types.Row{
+		{
+			Columns: []string{"seq_id", "zygosity", "ad_ratio", "filter"},
+			Values:  []interface{}{1, "HET", 0.5, "PASS"},
+		},
+	}, nil
+}
+
+func (m *MockRepository) CountVariantOccurrences(int, *types.Query) (int64, error) {
+	return 1, nil
+}
+
+func (m *MockRepository) GetSequencingExperiments(query *types.Query) ([]types.Row, error) {
+	m.lastQuery = query
+	return []types.Row{
+		{
+			Columns: []string{"seq_id", "patient_id", "family_id"},
+			Values:  []interface{}{1, "PA0001", "FM0001"},
+		},
+	}, nil
+}
+
+func (m *MockRepository) CountSequencingExperiments(*types.Query) (int64, error) {
+	return 1, nil
+}
+
+func (m *MockRepository) GetSequencingExperiment(seqId int) (*types.Row, error) {
+	if seqId != 1 {
+		return nil, nil
+	}
+	return &types.Row{Columns: []string{"seq_id", "patient_id", "analysis_date"}, Values: []interface{}{1, "PA0001", "2024-01-15"}}, nil
+}
+
+func (m *MockRepository) GetFamily(seqId int) (*types.Family, error) {
+	if seqId != 1 {
+		return nil, fmt.Errorf("family of sequencing experiment %d: %w", seqId, repository.ErrNotFound)
+	}
+	mother := 2
+	return &types.Family{ProbandSeqId: 1, MotherSeqId: &mother}, nil
+}
+
+func (m *MockRepository) GetFamilyOccurrences(_ int, query *types.Query) ([]types.Row, error) {
+	m.lastQuery = query
+	return []types.Row{
+		{
+			Columns: []string{"locus_id", "zygosity", "mother_zygosity", "father_zygosity"},
+			Values:  []interface{}{1000, "HET", "WT", nil},
+		},
+	}, nil
+}
+
+func (m *MockRepository) CountFamilyOccurrences(int, *types.Query) (int64, error) {
+	return 1, nil
+}
+
+func (m *MockRepository) GetCompoundHets(_ int, query *types.Query) ([]types.CompoundHetGene, int64, error) {
+	m.lastQuery = query
+	return []types.CompoundHetGene{
+		{
+			Symbol: "BRCA1",
+			Phased: true,
+			Variants: []types.Row{
+				{Columns: []string{"locus_id", "origin"}, Values: []interface{}{1000, types.MaternalOrigin}},
+				{Columns: []string{"locus_id", "origin"}, Values: []interface{}{1001, types.PaternalOrigin}},
+			},
+			Pairs:     [][2]int64{{1000, 1001}},
+			PairCount: 1,
+		},
+	}, 3, nil
+}
+
+func (m *MockRepository) GetCohort(cohortId string) ([]int, error) {
+	if cohortId != "C1" {
+		return nil, fmt.Errorf("cohort %s: %w", cohortId, repository.ErrNotFound)
+	}
+	return []int{1, 2, 3}, nil
+}
+
+func (m *MockRepository) CountCohortOccurrences(seqIds []int, query *types.Query) ([]types.SampleCount, error) {
+	m.lastQuery = query
+	counts := make([]types.SampleCount, len(seqIds))
+	for i, seqId := range seqIds {
+		counts[i] = types.SampleCount{SeqId: seqId, Count: int64(i)}
+	}
+	return counts, nil
+}
+
+func (m *MockRepository) GetCohortCarriers(_ []int, query *types.Query) ([]types.LocusCarriers, error) {
+	m.lastQuery = query
+	return []types.LocusCarriers{{LocusId: 1000, Carriers: 2, Het: 1, Hom: 1}}, nil
+}
+
+func (m *MockRepository) InvalidatePartCache(seqIds ...int) {
+	m.invalidations = append(m.invalidations, seqIds)
+}
+
+func (m *MockRepository) PartCacheStats() repository.PartCacheStats {
+	return repository.PartCacheStats{Hits: 5, Misses: 2, Size: 2}
+}
+
+func (m *MockRepository) AggregateFamilyOccurrences(_ int, query *types.Query, _ types.TermsOptions) (types.TermsAggregation, error) {
+	m.lastQuery = query
+	return types.TermsAggregation{Buckets: []types.Aggregation{{Bucket: types.DeNovo, Count: 2}}, OtherCount: 0}, nil
 }
 
 func TestStatusHandler(t *testing.T) {
@@ -85,6 +257,199 @@ func TestOccurrencesListHandler(t *testing.T) {
     }]`, w.Body.String())
 }
 
+func TestOccurrencesListHandlerKeepsFieldOrder(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/list", OccurrencesListHandler(repo))
+	req, _ := http.NewRequest("POST", "/occurrences/1/list", bytes.NewBuffer([]byte("{}")))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `[{"seq_id":1,"locus_id":1000,"filter":"PASS","zygosity":"HET","pf":0.99,"af":0.01,"hgvsg":"hgvsg1","ad_ratio":1,"variant_class":"class1"}]`, w.Body.String())
+}
+
+func TestOccurrencesListHandlerOffsetWithoutLimit(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/list", OccurrencesListHandler(repo))
+	req, _ := http.NewRequest("POST", "/occurrences/1/list", bytes.NewBuffer([]byte(`{"offset": 20}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, &types.Pagination{Limit: 10, Offset: 20}, repo.lastQuery.Pagination)
+}
+
+// nextPageRepository returns one more row than the mock repository, as when there is a next page
+type nextPageRepository struct {
+	MockRepository
+}
+
+func (r *nextPageRepository) GetOccurrences(seqId int, query *types.Query) ([]types.Row, error) {
+	rows, err := r.MockRepository.GetOccurrences(seqId, query)
+	next := types.Row{Columns: rows[0].Columns, Values: rows[0].Values, Keyset: []interface{}{1001}}
+	return append(rows, next), err
+}
+
+func TestOccurrencesListHandlerCursor(t *testing.T) {
+	repo := &nextPageRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/list", OccurrencesListHandler(repo))
+	req, _ := http.NewRequest("POST", "/occurrences/1/list", bytes.NewBuffer([]byte(`{"selected_fields":["locus_id"], "limit": 1, "cursor": ""}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	expectedCursor, _ := types.EncodeCursor([]interface{}{1000})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data":[{
+        "seq_id": 1,
+        "locus_id": 1000,
+        "filter": "PASS",
+        "zygosity": "HET",
+        "pf": 0.99,
+        "af": 0.01,
+        "hgvsg": "hgvsg1",
+        "ad_ratio": 1.0,
+        "variant_class": "class1"
+    }], "next_cursor": "`+expectedCursor+`"}`, w.Body.String())
+	assert.Equal(t, []types.SortField{{Field: types.LocusIdField, Order: "asc"}}, repo.lastQuery.SortedFields)
+}
+
+func TestOccurrencesListHandlerCursorLastPage(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/list", OccurrencesListHandler(repo))
+	cursor, _ := types.EncodeCursor([]interface{}{999})
+	req, _ := http.NewRequest("POST", "/occurrences/1/list", bytes.NewBuffer([]byte(`{"limit": 10, "cursor": "`+cursor+`"}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"next_cursor":null`)
+	assert.Equal(t, []interface{}{int64(999)}, repo.lastQuery.Pagination.Cursor.Values)
+}
+
+func TestOccurrencesListHandlerCursorLastPageOfExactlyLimitRows(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/list", OccurrencesListHandler(repo))
+	req, _ := http.NewRequest("POST", "/occurrences/1/list", bytes.NewBuffer([]byte(`{"limit": 1, "cursor": ""}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"next_cursor":null`)
+}
+
+func TestOccurrencesListHandlerNegativeLimit(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/list", OccurrencesListHandler(repo))
+	req, _ := http.NewRequest("POST", "/occurrences/1/list", bytes.NewBuffer([]byte(`{"selected_fields":["locus_id"], "limit": -1}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "limit and offset must not be negative"}`, w.Body.String())
+	assert.Nil(t, repo.lastQuery)
+}
+
+func TestOccurrencesListHandlerCursorNegativeLimit(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/list", OccurrencesListHandler(repo))
+	req, _ := http.NewRequest("POST", "/occurrences/1/list", bytes.NewBuffer([]byte(`{"selected_fields":["locus_id"], "limit": -1, "cursor": ""}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "limit and offset must not be negative"}`, w.Body.String())
+	assert.Nil(t, repo.lastQuery)
+}
+
+func TestOccurrencesListHandlerInvalidCursor(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/list", OccurrencesListHandler(repo))
+	req, _ := http.NewRequest("POST", "/occurrences/1/list", bytes.NewBuffer([]byte(`{"cursor": "invalid"}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestOccurrencesListHandlerEnvelope(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/list", OccurrencesListHandler(repo))
+	body := `{"selected_fields":["locus_id"], "limit": 1, "offset": 2, "sort": [{"field": "pf", "order": "desc"}], "envelope": true}`
+	req, _ := http.NewRequest("POST", "/occurrences/1/list", bytes.NewBuffer([]byte(body)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"data": [{"seq_id": 1, "locus_id": 1000, "filter": "PASS", "zygosity": "HET", "pf": 0.99, "af": 0.01, "hgvsg": "hgvsg1", "ad_ratio": 1.0, "variant_class": "class1"}],
+		"total": 15,
+		"limit": 1,
+		"offset": 2,
+		"has_more": true,
+		"sort": [{"field": "pf", "order": "desc"}]
+	}`, w.Body.String())
+}
+
+func TestOccurrencesListHandlerEnvelopeWithCursor(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/list", OccurrencesListHandler(repo))
+	body := `{"selected_fields":["locus_id"], "limit": 500, "cursor": "", "envelope": true}`
+	req, _ := http.NewRequest("POST", "/occurrences/1/list", bytes.NewBuffer([]byte(body)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"data": [{"seq_id": 1, "locus_id": 1000, "filter": "PASS", "zygosity": "HET", "pf": 0.99, "af": 0.01, "hgvsg": "hgvsg1", "ad_ratio": 1.0, "variant_class": "class1"}],
+		"total": 15,
+		"limit": 200,
+		"offset": 0,
+		"has_more": false,
+		"sort": [{"field": "locus_id", "order": "asc"}]
+	}`, w.Body.String())
+}
+
+// concurrentRepository fails to list occurrences unless they are counted at the same time
+type concurrentRepository struct {
+	MockRepository
+	counting chan struct{}
+}
+
+func (m *concurrentRepository) GetOccurrences(seqId int, query *types.Query) ([]types.Row, error) {
+	select {
+	case <-m.counting:
+		return m.MockRepository.GetOccurrences(seqId, query)
+	case <-time.After(time.Second):
+		return nil, errors.New("occurrences were not counted concurrently")
+	}
+}
+
+func (m *concurrentRepository) CountOccurrences(seqId int, query *types.Query) (int64, error) {
+	close(m.counting)
+	return m.MockRepository.CountOccurrences(seqId, query)
+}
+
+func TestOccurrencesListHandlerEnvelopeIsConcurrent(t *testing.T) {
+	repo := &concurrentRepository{counting: make(chan struct{})}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/list", OccurrencesListHandler(repo))
+	req, _ := http.NewRequest("POST", "/occurrences/1/list", bytes.NewBuffer([]byte(`{"envelope": true}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
 func TestOccurrencesCountHandler(t *testing.T) {
 	repo := &MockRepository{}
 	router := gin.Default()
@@ -116,7 +481,459 @@ func TestOccurrencesAggregateHandler(t *testing.T) {
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
-	expected := `[{"key": "HET", "count": 2}, {"key": "HOM", "count": 1}]`
+	expected := `{"buckets": [{"key": "HET", "count": 2}, {"key": "HOM", "count": 1}], "other_count": 3}`
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, expected, w.Body.String())
+}
+
+func TestOccurrencesAggregateHandlerMissing(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/aggregate", OccurrencesAggregateHandler(repo))
+
+	body := `{"field": "zygosity", "size": 2, "order": "key_asc", "missing": true}`
+	req, _ := http.NewRequest("POST", "/occurrences/1/aggregate", bytes.NewBuffer([]byte(body)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	expected := `{"buckets": [{"key": "HET", "count": 2}, {"key": "HOM", "count": 1}], "other_count": 3, "missing": 4}`
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, expected, w.Body.String())
+}
+
+func TestOccurrencesAggregateHandlerInvalidOrder(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/aggregate", OccurrencesAggregateHandler(repo))
+
+	req, _ := http.NewRequest("POST", "/occurrences/1/aggregate", bytes.NewBuffer([]byte(`{"field": "zygosity", "order": "random"}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "invalid order: random"}`, w.Body.String())
+}
+
+func TestOccurrencesAggregateHandlerTooLargeSize(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/aggregate", OccurrencesAggregateHandler(repo))
+
+	req, _ := http.NewRequest("POST", "/occurrences/1/aggregate", bytes.NewBuffer([]byte(`{"field": "zygosity", "size": 5000}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "size must be between 0 and 1000"}`, w.Body.String())
+}
+
+func TestOccurrencesAggregateHandlerUnknownField(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/aggregate", OccurrencesAggregateHandler(repo))
+
+	req, _ := http.NewRequest("POST", "/occurrences/1/aggregate", bytes.NewBuffer([]byte(`{"field": "unknown"}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "unauthorized or unknown field: unknown"}`, w.Body.String())
+}
+
+func TestOccurrencesListHandlerInvalidValueType(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/list", OccurrencesListHandler(repo))
+	body := `{
+			"sqon":{
+				"op":">=",
+				"field": "ad_ratio",
+				"value": "high"
+			}
+	}`
+	req, _ := http.NewRequest("POST", "/occurrences/1/list", bytes.NewBuffer([]byte(body)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"invalid value high for field ad_ratio with operation >=: expected decimal"}`, w.Body.String())
+}
+
+func TestOccurrencesFacetsHandler(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/facets", OccurrencesFacetsHandler(repo))
+
+	body := `{
+			"fields": ["zygosity", "filter"],
+			"sqon":{
+				"op":"in",
+				"field": "filter",
+				"value": "PASS"
+		    },
+			"size": 10
+	}`
+	req, _ := http.NewRequest("POST", "/occurrences/1/facets", bytes.NewBuffer([]byte(body)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	buckets := `{"buckets": [{"key": "HET", "count": 2}, {"key": "HOM", "count": 1}], "other_count": 3}`
+	expected := `{"zygosity": ` + buckets + `, "filter": ` + buckets + `}`
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, expected, w.Body.String())
+}
+
+func TestOccurrencesFacetsHandlerUnknownField(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/facets", OccurrencesFacetsHandler(repo))
+
+	req, _ := http.NewRequest("POST", "/occurrences/1/facets", bytes.NewBuffer([]byte(`{"fields": ["zygosity", "unknown"]}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "unauthorized or unknown field: unknown"}`, w.Body.String())
+}
+
+func TestOccurrencesFacetsHandlerNoField(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/facets", OccurrencesFacetsHandler(repo))
+
+	req, _ := http.NewRequest("POST", "/occurrences/1/facets", bytes.NewBuffer([]byte(`{"fields": []}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "fields must contain between 1 and 20 fields"}`, w.Body.String())
+}
+
+// boundedRepository records the maximum number of aggregations running at the same time. The aggregations signal
+// entered when they start and wait for release to be closed before returning.
+type boundedRepository struct {
+	MockRepository
+	running    atomic.Int32
+	maxRunning atomic.Int32
+	entered    chan struct{}
+	release    chan struct{}
+}
+
+func (m *boundedRepository) AggregateOccurrences(seqId int, query *types.Query, options types.TermsOptions) (types.TermsAggregation, error) {
+	running := m.running.Add(1)
+	defer m.running.Add(-1)
+	for {
+		current := m.maxRunning.Load()
+		if running <= current || m.maxRunning.CompareAndSwap(current, running) {
+			break
+		}
+	}
+	m.entered <- struct{}{}
+	<-m.release
+	return types.TermsAggregation{Buckets: []types.Aggregation{{Bucket: query.SelectedFields[0].Name, Count: 1}}}, nil
+}
+
+func TestAggregateFacetsIsBounded(t *testing.T) {
+	var facets []types.Field
+	for _, field := range types.OccurrencesFields {
+		if field.CanBeSelected {
+			facets = append(facets, field)
+		}
+	}
+	repo := &boundedRepository{entered: make(chan struct{}, len(facets)), release: make(chan struct{})}
+	var (
+		aggregations map[string]types.TermsAggregation
+		err          error
+		done         = make(chan struct{})
+	)
+	go func() {
+		aggregations, err = aggregateFacets(repo, 1, &types.Query{}, facets, types.TermsOptions{}, false)
+		close(done)
+	}()
+	// No aggregation can return before the release, so MaxConcurrentFacets of them are running at the same time
+	for i := 0; i < MaxConcurrentFacets; i++ {
+		<-repo.entered
+	}
+	close(repo.release)
+	<-done
+
+	assert.NoError(t, err)
+	assert.Len(t, aggregations, len(facets))
+	assert.Equal(t, types.AdRatioField.Name, aggregations[types.AdRatioField.GetAlias()].Buckets[0].Bucket)
+	assert.EqualValues(t, MaxConcurrentFacets, repo.maxRunning.Load())
+}
+
+// failingRepository fails to aggregate the facets on the filter field
+type failingRepository struct {
+	MockRepository
+}
+
+func (m *failingRepository) AggregateOccurrences(seqId int, query *types.Query, options types.TermsOptions) (types.TermsAggregation, error) {
+	if query.SelectedFields[0].Name == types.FilterField.Name {
+		return types.TermsAggregation{}, errors.New("aggregation failed")
+	}
+	return m.MockRepository.AggregateOccurrences(seqId, query, options)
+}
+
+func TestOccurrencesFacetsHandlerError(t *testing.T) {
+	repo := &failingRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/facets", OccurrencesFacetsHandler(repo))
+
+	req, _ := http.NewRequest("POST", "/occurrences/1/facets", bytes.NewBuffer([]byte(`{"fields": ["zygosity", "filter"]}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.JSONEq(t, `{"error": "internal server error"}`, w.Body.String())
+}
+
+// queriesRepository records the queries of the aggregations by field
+type queriesRepository struct {
+	MockRepository
+	mutex   sync.Mutex
+	queries map[string]types.Query
+}
+
+func (m *queriesRepository) AggregateOccurrences(seqId int, query *types.Query, options types.TermsOptions) (types.TermsAggregation, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.queries == nil {
+		m.queries = map[string]types.Query{}
+	}
+	m.queries[query.SelectedFields[0].Name] = *query
+	return m.MockRepository.AggregateOccurrences(seqId, query, options)
+}
+
+const excludeSelfSQON = `{
+		"op": "and",
+		"content": [
+			{"op": "in", "field": "zygosity", "value": ["HET"]},
+			{"op": "in", "field": "filter", "value": ["PASS"]}
+		]
+	}`
+
+func TestOccurrencesAggregateHandlerExcludeSelf(t *testing.T) {
+	repo := &queriesRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/aggregate", OccurrencesAggregateHandler(repo))
+
+	body := `{"field": "zygosity", "exclude_self": true, "sqon": ` + excludeSelfSQON + `}`
+	req, _ := http.NewRequest("POST", "/occurrences/1/aggregate", bytes.NewBuffer([]byte(body)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	query := repo.queries["zygosity"]
+	sql, params := query.Filters.ToSQL()
+	assert.Equal(t, "o.filter = ?", sql)
+	assert.Equal(t, []interface{}{"PASS"}, params)
+	assert.Equal(t, []types.Field{types.FilterField}, query.FilteredFields)
+}
+
+func TestOccurrencesFacetsHandlerExcludeSelf(t *testing.T) {
+	repo := &queriesRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/facets", OccurrencesFacetsHandler(repo))
+
+	body := `{"fields": ["zygosity", "filter", "chromosome"], "exclude_self": true, "sqon": ` + excludeSelfSQON + `}`
+	req, _ := http.NewRequest("POST", "/occurrences/1/facets", bytes.NewBuffer([]byte(body)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	zygosityQuery := repo.queries["zygosity"]
+	sql, _ := zygosityQuery.Filters.ToSQL()
+	assert.Equal(t, "o.filter = ?", sql)
+
+	filterQuery := repo.queries["filter"]
+	sql, _ = filterQuery.Filters.ToSQL()
+	assert.Equal(t, "o.zygosity = ?", sql)
+
+	chromosomeQuery := repo.queries["chromosome"]
+	sql, _ = chromosomeQuery.Filters.ToSQL()
+	assert.Equal(t, "(o.zygosity = ? AND o.filter = ?)", sql)
+}
+
+func TestOccurrencesHistogramHandler(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/histogram", OccurrencesHistogramHandler(repo))
+
+	body := `{"field": "ad_ratio", "interval": 0.25, "sqon": {"op": "in", "field": "filter", "value": "PASS"}}`
+	req, _ := http.NewRequest("POST", "/occurrences/1/histogram", bytes.NewBuffer([]byte(body)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"buckets": [{"from": 0, "to": 0.25, "count": 5}]}`, w.Body.String())
+	assert.Equal(t, []types.Field{types.AdRatioField}, repo.lastQuery.SelectedFields)
+}
+
+func TestOccurrencesHistogramHandlerRanges(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/histogram", OccurrencesHistogramHandler(repo))
+
+	body := `{"field": "gnomad_v3_af", "ranges": [{"to": 0.01}, {"from": 0.01, "to": 0.05}, {"from": 0.05}]}`
+	req, _ := http.NewRequest("POST", "/occurrences/1/histogram", bytes.NewBuffer([]byte(body)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"buckets": [{"to": 0.01, "count": 1}, {"from": 0.01, "to": 0.05, "count": 1}, {"from": 0.05, "count": 1}]}`, w.Body.String())
+}
+
+func TestOccurrencesHistogramHandlerLogScale(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/histogram", OccurrencesHistogramHandler(repo))
+
+	req, _ := http.NewRequest("POST", "/occurrences/1/histogram", bytes.NewBuffer([]byte(`{"field": "pf", "log_scale": true}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"buckets": [{"from": 0, "to": 1, "count": 5}]}`, w.Body.String())
+}
+
+// tooManyBucketsRepository fails the histograms as when the interval is too small for the values
+type tooManyBucketsRepository struct {
+	MockRepository
+}
+
+func (m *tooManyBucketsRepository) HistogramOccurrences(int, *types.Query, types.HistogramOptions) (types.Histogram, error) {
+	return types.Histogram{}, fmt.Errorf("2501 buckets: %w", repository.ErrTooManyBuckets)
+}
+
+func TestOccurrencesHistogramHandlerTooManyBuckets(t *testing.T) {
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/histogram", OccurrencesHistogramHandler(&tooManyBucketsRepository{}))
+
+	req, _ := http.NewRequest("POST", "/occurrences/1/histogram", bytes.NewBuffer([]byte(`{"field": "dp", "interval": 0.01}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "more than 1000 buckets, increase the interval"}`, w.Body.String())
+}
+
+func testHistogramBadRequest(t *testing.T, body string, expected string) {
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/histogram", OccurrencesHistogramHandler(&MockRepository{}))
+
+	req, _ := http.NewRequest("POST", "/occurrences/1/histogram", bytes.NewBuffer([]byte(body)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, fmt.Sprintf(`{"error": %q}`, expected), w.Body.String())
+}
+
+func TestOccurrencesHistogramHandlerNonNumericField(t *testing.T) {
+	testHistogramBadRequest(t, `{"field": "zygosity", "interval": 1}`, "histogram is not supported for field: zygosity")
+}
+
+func TestOccurrencesHistogramHandlerInvalidInterval(t *testing.T) {
+	testHistogramBadRequest(t, `{"field": "dp"}`, "interval must be positive")
+	testHistogramBadRequest(t, `{"field": "dp", "interval": -5}`, "interval must be positive")
+}
+
+func TestOccurrencesHistogramHandlerInvalidRanges(t *testing.T) {
+	testHistogramBadRequest(t, `{"field": "dp", "interval": 5, "ranges": [{"to": 10}]}`, "ranges cannot be combined with interval or log_scale")
+	testHistogramBadRequest(t, `{"field": "dp", "ranges": [{}]}`, "a range must have a from or a to bound")
+	testHistogramBadRequest(t, `{"field": "dp", "ranges": [{"from": 10, "to": 10}]}`, "invalid range [10, 10): from must be lower than to")
+}
+
+func TestOccurrencesStatsHandler(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/stats", OccurrencesStatsHandler(repo))
+
+	body := `{"fields": ["ad_ratio", "dp"], "percentiles": [0.5], "sqon": {"op": "in", "field": "filter", "value": "PASS"}}`
+	req, _ := http.NewRequest("POST", "/occurrences/1/stats", bytes.NewBuffer([]byte(body)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	stats := `{"count": 2, "min": 0, "max": 1, "mean": 1, "stddev": 0, "percentiles": [{"percentile": 0.5, "value": 1}]}`
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"ad_ratio": `+stats+`, "dp": `+stats+`}`, w.Body.String())
+	assert.Equal(t, []types.Field{types.AdRatioField, types.DpField}, repo.lastQuery.SelectedFields)
+}
+
+func TestOccurrencesStatsHandlerDefaultPercentiles(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/stats", OccurrencesStatsHandler(repo))
+
+	req, _ := http.NewRequest("POST", "/occurrences/1/stats", bytes.NewBuffer([]byte(`{"fields": ["gq"]}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	expected := `{"gq": {"count": 2, "min": 0, "max": 1, "mean": 1, "stddev": 0, "percentiles": [
+		{"percentile": 0.25, "value": 1}, {"percentile": 0.5, "value": 1}, {"percentile": 0.75, "value": 1}
+	]}}`
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.JSONEq(t, expected, w.Body.String())
 }
+
+func testStatsBadRequest(t *testing.T, body string, expected string) {
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/stats", OccurrencesStatsHandler(&MockRepository{}))
+
+	req, _ := http.NewRequest("POST", "/occurrences/1/stats", bytes.NewBuffer([]byte(body)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, fmt.Sprintf(`{"error": %q}`, expected), w.Body.String())
+}
+
+func TestOccurrencesStatsHandlerInvalidField(t *testing.T) {
+	testStatsBadRequest(t, `{"fields": ["dp", "zygosity"]}`, "stats are not supported for field: zygosity")
+	testStatsBadRequest(t, `{"fields": ["unknown"]}`, "unauthorized or unknown field: unknown")
+	testStatsBadRequest(t, `{"fields": []}`, "fields must contain between 1 and 20 fields")
+}
+
+func TestOccurrencesStatsHandlerInvalidPercentile(t *testing.T) {
+	testStatsBadRequest(t, `{"fields": ["dp"], "percentiles": [50]}`, "invalid percentile 50: expected a value between 0 and 1")
+}
+
+// unknownExperimentRepository fails as if the sequencing experiment does not exist
+type unknownExperimentRepository struct {
+	MockRepository
+}
+
+func (m *unknownExperimentRepository) GetOccurrences(seqId int, _ *types.Query) ([]types.Row, error) {
+	return nil, fmt.Errorf("error during query preparation %w", fmt.Errorf("sequencing experiment %d: %w", seqId, repository.ErrNotFound))
+}
+
+func (m *unknownExperimentRepository) CountOccurrences(seqId int, _ *types.Query) (int64, error) {
+	return 0, fmt.Errorf("sequencing experiment %d: %w", seqId, repository.ErrNotFound)
+}
+
+func TestOccurrencesListHandlerUnknownExperiment(t *testing.T) {
+	repo := &unknownExperimentRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/list", OccurrencesListHandler(repo))
+
+	req, _ := http.NewRequest("POST", "/occurrences/42/list", bytes.NewBuffer([]byte("{}")))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error": "not found"}`, w.Body.String())
+}
+
+func TestOccurrencesCountHandlerUnknownExperiment(t *testing.T) {
+	repo := &unknownExperimentRepository{}
+	router := gin.Default()
+	router.POST("/occurrences/:seq_id/count", OccurrencesCountHandler(repo))
+
+	req, _ := http.NewRequest("POST", "/occurrences/42/count", bytes.NewBuffer([]byte("{}")))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error": "not found"}`, w.Body.String())
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+	"go-poc/internal/repository"
+	"go-poc/internal/types"
+	"net/http"
+	"strconv"
+)
+
+func SequencingExperimentsListHandler(repo repository.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			body  types.ListBody
+			query types.Query
+		)
+
+		// Bind JSON to the struct
+		if err := c.ShouldBindJSON(&body); err != nil {
+			// Return a 400 Bad Request if validation fails
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		p, err := listPagination(&body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		query, err = types.BuildQuery(body.SelectedFields, body.SQON, &types.SequencingExperimentFields, &p, body.Sort)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		writeList(c, body.Envelope, &query, repo.GetSequencingExperiments, repo.CountSequencingExperiments)
+	}
+}
+
+func SequencingExperimentsCountHandler(repo repository.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			body  types.CountBody
+			query types.Query
+		)
+
+		// Bind JSON to the struct
+		if err := c.ShouldBindJSON(&body); err != nil {
+			// Return a 400 Bad Request if validation fails
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		query, err := types.BuildQuery(nil, body.SQON, &types.SequencingExperimentFields, nil, nil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		count, err := repo.CountSequencingExperiments(&query)
+		if err != nil {
+			writeRepositoryError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"count": count})
+	}
+}
+
+// SequencingExperimentsSearchHandler lists the sequencing experiments whose patient or family id starts with the q parameter, ignoring case,
+// and matching the sqon parameter if any
+func SequencingExperimentsSearchHandler(repo repository.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		q := c.Query("q")
+		if q == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "q must be defined"})
+			return
+		}
+		body, err := listBodyFromQueryString(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		search := types.SQON{
+			Op: "or",
+			Content: []types.SQON{
+				{Op: "iprefix", Field: types.PatientIdField.Name, Value: q},
+				{Op: "iprefix", Field: types.FamilyIdField.Name, Value: q},
+			},
+		}
+		if body.SQON != nil {
+			body.SQON = &types.SQON{Op: "and", Content: []types.SQON{search, *body.SQON}}
+		} else {
+			body.SQON = &search
+		}
+		if len(body.SelectedFields) == 0 {
+			body.SelectedFields = []string{types.ExperimentSeqIdField.Name, types.PatientIdField.Name, types.FamilyIdField.Name}
+		}
+		p, err := listPagination(&body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		query, err := types.BuildQuery(body.SelectedFields, body.SQON, &types.SequencingExperimentFields, &p, nil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		writeList(c, body.Envelope, &query, repo.GetSequencingExperiments, repo.CountSequencingExperiments)
+	}
+}
+
+func SequencingExperimentHandler(repo repository.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		seqID, err := strconv.Atoi(c.Param("seq_id"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		experiment, err := repo.GetSequencingExperiment(seqID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if experiment == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		c.JSON(http.StatusOK, experiment)
+	}
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"go-poc/internal/types"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSequencingExperimentsListHandler(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/sequencing/list", SequencingExperimentsListHandler(repo))
+
+	body := `{
+			"selected_fields": ["seq_id", "patient_id", "family_id"],
+			"sqon": {"op": ">=", "field": "analysis_date", "value": "2024-01-01"},
+			"sort": [{"field": "analysis_date", "order": "desc"}]
+		}`
+	req, _ := http.NewRequest("POST", "/sequencing/list", bytes.NewBuffer([]byte(body)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"seq_id": 1, "patient_id": "PA0001", "family_id": "FM0001"}]`, w.Body.String())
+	sql, params := repo.lastQuery.Filters.ToSQL()
+	assert.Equal(t, "s.analysis_date >= ?", sql)
+	assert.Equal(t, []interface{}{"2024-01-01"}, params)
+	assert.Equal(t, []types.SortField{{Field: types.AnalysisDateField, Order: "desc"}}, repo.lastQuery.SortedFields)
+}
+
+func TestSequencingExperimentsListHandlerInvalidDate(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/sequencing/list", SequencingExperimentsListHandler(repo))
+
+	body := `{"sqon": {"op": ">=", "field": "analysis_date", "value": "01/01/2024"}}`
+	req, _ := http.NewRequest("POST", "/sequencing/list", bytes.NewBuffer([]byte(body)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "invalid value 01/01/2024 for field analysis_date with operation >=: expected date formatted as YYYY-MM-DD"}`, w.Body.String())
+}
+
+func TestSequencingExperimentsCountHandler(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/sequencing/count", SequencingExperimentsCountHandler(repo))
+
+	req, _ := http.NewRequest("POST", "/sequencing/count", bytes.NewBuffer([]byte(`{"sqon": {"op": "in", "field": "status", "value": ["completed"]}}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"count": 1}`, w.Body.String())
+}
+
+// failingSequencingRepository fails to count the sequencing experiments
+type failingSequencingRepository struct {
+	MockRepository
+}
+
+func (m *failingSequencingRepository) CountSequencingExperiments(*types.Query) (int64, error) {
+	return 0, errors.New("count failed")
+}
+
+func TestSequencingExperimentsCountHandlerError(t *testing.T) {
+	router := gin.Default()
+	router.POST("/sequencing/count", SequencingExperimentsCountHandler(&failingSequencingRepository{}))
+
+	req, _ := http.NewRequest("POST", "/sequencing/count", bytes.NewBuffer([]byte(`{}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.JSONEq(t, `{"error": "internal server error"}`, w.Body.String())
+}
+
+func TestSequencingExperimentsSearchHandler(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.GET("/sequencing/search", SequencingExperimentsSearchHandler(repo))
+
+	req, _ := http.NewRequest("GET", "/sequencing/search?q=pa00&limit=5", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"seq_id": 1, "patient_id": "PA0001", "family_id": "FM0001"}]`, w.Body.String())
+	sql, params := repo.lastQuery.Filters.ToSQL()
+	assert.Equal(t, `(lower(s.patient_id) LIKE ? OR lower(s.family_id) LIKE ?)`, sql)
+	assert.Equal(t, []interface{}{"pa00%", "pa00%"}, params)
+	assert.Equal(t, 5, repo.lastQuery.Pagination.Limit)
+}
+
+func TestSequencingExperimentsSearchHandlerWithSqon(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.GET("/sequencing/search", SequencingExperimentsSearchHandler(repo))
+
+	sqon := url.QueryEscape(`{"op": "in", "field": "status", "value": ["completed"]}`)
+	req, _ := http.NewRequest("GET", "/sequencing/search?q=pa00&sqon="+sqon, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	sql, params := repo.lastQuery.Filters.ToSQL()
+	assert.Equal(t, `((lower(s.patient_id) LIKE ? OR lower(s.family_id) LIKE ?) AND s.status = ?)`, sql)
+	assert.Equal(t, []interface{}{"pa00%", "pa00%", "completed"}, params)
+}
+
+func TestSequencingExperimentsSearchHandlerMissingQ(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.GET("/sequencing/search", SequencingExperimentsSearchHandler(repo))
+
+	req, _ := http.NewRequest("GET", "/sequencing/search", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "q must be defined"}`, w.Body.String())
+}
+
+func TestSequencingExperimentHandler(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.GET("/sequencing/:seq_id", SequencingExperimentHandler(repo))
+
+	req, _ := http.NewRequest("GET", "/sequencing/1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"seq_id": 1, "patient_id": "PA0001", "analysis_date": "2024-01-15"}`, w.Body.String())
+}
+
+func TestSequencingExperimentHandlerNotFound(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.GET("/sequencing/:seq_id", SequencingExperimentHandler(repo))
+
+	req, _ := http.NewRequest("GET", "/sequencing/2", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error": "not found"}`, w.Body.String())
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go-poc/internal/repository"
+	"go-poc/internal/types"
+	"net/http"
+	"strconv"
+)
+
+// DefaultCarrierFields are the fields returned for each carrier of a variant when none is selected
+var DefaultCarrierFields = []string{"seq_id", "zygosity", "ad_ratio", "filter"}
+
+// carrierSort is the order of the carriers of a variant, which makes the pages stable
+var carrierSort = []types.SortBody{{Field: "seq_id", Order: "asc"}}
+
+func VariantsListHandler(repo repository.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			body  types.ListBody
+			query types.Query
+		)
+
+		// Bind JSON to the struct
+		if err := c.ShouldBindJSON(&body); err != nil {
+			// Return a 400 Bad Request if validation fails
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		p, err := listPagination(&body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		query, err = types.BuildQuery(body.SelectedFields, body.SQON, &types.VariantsFields, &p, body.Sort)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		writeList(c, body.Envelope, &query, repo.GetVariants, repo.CountVariants)
+	}
+}
+
+func VariantsCountHandler(repo repository.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			body  types.CountBody
+			query types.Query
+		)
+
+		// Bind JSON to the struct
+		if err := c.ShouldBindJSON(&body); err != nil {
+			// Return a 400 Bad Request if validation fails
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		query, err := types.BuildQuery(nil, body.SQON, &types.VariantsFields, nil, nil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		count, err := repo.CountVariants(&query)
+		if err != nil {
+			writeRepositoryError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"count": count})
+	}
+}
+
+func VariantsAggregateHandler(repo repository.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			body  types.AggregationBody
+			query types.Query
+		)
+
+		// Bind JSON to the struct
+		if err := c.ShouldBindJSON(&body); err != nil {
+			// Return a 400 Bad Request if validation fails
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		selected := []string{body.Field}
+		query, err := types.BuildQuery(selected, body.SQON, &types.VariantsFields, nil, nil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if len(query.SelectedFields) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unauthorized or unknown field: %s", body.Field)})
+			return
+		}
+		options := types.TermsOptions{Size: body.Size, Order: body.Order, Missing: body.Missing}
+		if err := validateTermsOptions(options); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if body.ExcludeSelf {
+			query = query.WithoutFiltersOn(query.SelectedFields[0])
+		}
+		aggregation, err := repo.AggregateVariants(&query, options)
+		if err != nil {
+			writeRepositoryError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, aggregation)
+	}
+}
+
+func VariantHandler(repo repository.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locusID, err := strconv.Atoi(c.Param("locus_id"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		variant, err := repo.GetVariant(locusID)
+		if err != nil {
+			writeRepositoryError(c, err)
+			return
+		}
+		if variant == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		c.JSON(http.StatusOK, variant)
+	}
+}
+
+func VariantOccurrencesHandler(repo repository.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locusID, err := strconv.Atoi(c.Param("locus_id"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		body, err := listBodyFromQueryString(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if len(body.SelectedFields) == 0 {
+			body.SelectedFields = DefaultCarrierFields
+		}
+		if cursor, ok := c.GetQuery("cursor"); ok {
+			body.Cursor = &cursor
+		}
+		p, err := listPagination(&body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		query, err := types.BuildQuery(body.SelectedFields, body.SQON, &types.CarrierFields, &p, carrierSort)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		writeList(c, body.Envelope, &query,
+			func(query *types.Query) ([]types.Row, error) { return repo.GetVariantOccurrences(locusID, query) },
+			func(query *types.Query) (int64, error) { return repo.CountVariantOccurrences(locusID, query) },
+		)
+	}
+}
@@ -0,0 +1,256 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go-poc/internal/repository"
+	"go-poc/internal/types"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVariantsListHandler(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/variants/list", VariantsListHandler(repo))
+
+	body := `{
+			"selected_fields": ["locus_id", "symbol", "pf"],
+			"sqon": {"op": "in", "field": "symbol", "value": ["BRCA1"]},
+			"sort": [{"field": "pf", "order": "desc"}]
+		}`
+	req, _ := http.NewRequest("POST", "/variants/list", bytes.NewBuffer([]byte(body)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"locus_id": 1000, "symbol": "BRCA1", "pf": 0.5}]`, w.Body.String())
+	assert.Equal(t, []types.Field{types.VariantLocusIdField, types.SymbolField, types.PfField}, repo.lastQuery.SelectedFields)
+	sql, _ := repo.lastQuery.Filters.ToSQL()
+	assert.Equal(t, "v.symbol = ?", sql)
+}
+
+func TestVariantsListHandlerEnvelope(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/variants/list", VariantsListHandler(repo))
+
+	req, _ := http.NewRequest("POST", "/variants/list", bytes.NewBuffer([]byte(`{"envelope": true, "limit": 1}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	expected := `{"data": [{"locus_id": 1000, "symbol": "BRCA1", "pf": 0.5}], "total": 8, "limit": 1, "offset": 0, "has_more": true, "sort": []}`
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, expected, w.Body.String())
+}
+
+func TestVariantsListHandlerOccurrenceField(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/variants/list", VariantsListHandler(repo))
+
+	body := `{"sqon": {"op": "in", "field": "zygosity", "value": ["HET"]}}`
+	req, _ := http.NewRequest("POST", "/variants/list", bytes.NewBuffer([]byte(body)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "unauthorized or unknown field: zygosity"}`, w.Body.String())
+}
+
+func TestVariantsCountHandler(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/variants/count", VariantsCountHandler(repo))
+
+	req, _ := http.NewRequest("POST", "/variants/count", bytes.NewBuffer([]byte("{}")))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"count": 8}`, w.Body.String())
+}
+
+func TestVariantsAggregateHandler(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.POST("/variants/aggregate", VariantsAggregateHandler(repo))
+
+	req, _ := http.NewRequest("POST", "/variants/aggregate", bytes.NewBuffer([]byte(`{"field": "symbol", "size": 1}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"buckets": [{"key": "BRCA1", "count": 3}], "other_count": 1}`, w.Body.String())
+	assert.Equal(t, []types.Field{types.SymbolField}, repo.lastQuery.SelectedFields)
+}
+
+func TestVariantHandler(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.GET("/variants/:locus_id", VariantHandler(repo))
+
+	req, _ := http.NewRequest("GET", "/variants/1000", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"locus_id": 1000, "symbol": "BRCA1"}`, w.Body.String())
+}
+
+func TestVariantHandlerNotFound(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.GET("/variants/:locus_id", VariantHandler(repo))
+
+	req, _ := http.NewRequest("GET", "/variants/2000", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error": "not found"}`, w.Body.String())
+}
+
+// failingVariantsRepository fails to count the variants and to fetch the variant
+type failingVariantsRepository struct {
+	MockRepository
+}
+
+func (m *failingVariantsRepository) CountVariants(*types.Query) (int64, error) {
+	return 0, errors.New("count failed")
+}
+
+func (m *failingVariantsRepository) GetVariant(locusId int) (*types.Row, error) {
+	return nil, fmt.Errorf("variant %d: %w", locusId, repository.ErrNotFound)
+}
+
+func TestVariantsCountHandlerError(t *testing.T) {
+	router := gin.Default()
+	router.POST("/variants/count", VariantsCountHandler(&failingVariantsRepository{}))
+
+	req, _ := http.NewRequest("POST", "/variants/count", bytes.NewBuffer([]byte("{}")))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.JSONEq(t, `{"error": "internal server error"}`, w.Body.String())
+}
+
+func TestVariantHandlerRepositoryNotFound(t *testing.T) {
+	router := gin.Default()
+	router.GET("/variants/:locus_id", VariantHandler(&failingVariantsRepository{}))
+
+	req, _ := http.NewRequest("GET", "/variants/1000", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error": "not found"}`, w.Body.String())
+}
+
+func TestVariantOccurrencesHandler(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.GET("/variants/:locus_id/occurrences", VariantOccurrencesHandler(repo))
+
+	sqon := url.QueryEscape(`{"op": "in", "field": "zygosity", "value": ["HET"]}`)
+	req, _ := http.NewRequest("GET", "/variants/1000/occurrences?limit=5&offset=10&sqon="+sqon, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"seq_id": 1, "zygosity": "HET", "ad_ratio": 0.5, "filter": "PASS"}]`, w.Body.String())
+	assert.Equal(t, []types.Field{types.CarrierSeqIdField, types.ZygosityField, types.AdRatioField, types.FilterField}, repo.lastQuery.SelectedFields)
+	assert.Equal(t, &types.Pagination{Limit: 5, Offset: 10}, repo.lastQuery.Pagination)
+	sql, _ := repo.lastQuery.Filters.ToSQL()
+	assert.Equal(t, "o.zygosity = ?", sql)
+}
+
+func TestVariantOccurrencesHandlerEnvelope(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.GET("/variants/:locus_id/occurrences", VariantOccurrencesHandler(repo))
+
+	req, _ := http.NewRequest("GET", "/variants/1000/occurrences?selected_fields=seq_id,zygosity&envelope=true", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	expected := `{"data": [{"seq_id": 1, "zygosity": "HET", "ad_ratio": 0.5, "filter": "PASS"}], "total": 1, "limit": 10, "offset": 0, "has_more": false, "sort": [{"field": "seq_id", "order": "asc"}]}`
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, expected, w.Body.String())
+	assert.Equal(t, []types.Field{types.CarrierSeqIdField, types.ZygosityField}, repo.lastQuery.SelectedFields)
+}
+
+// carriersNextPageRepository returns one more carrier than the limit, as when there is a next page
+type carriersNextPageRepository struct {
+	MockRepository
+}
+
+func (m *carriersNextPageRepository) GetVariantOccurrences(_ int, query *types.Query) ([]types.Row, error) {
+	m.lastQuery = query
+	return []types.Row{
+		{Columns: []string{"seq_id"}, Values: []interface{}{2}, Keyset: []interface{}{2, 1000}},
+		{Columns: []string{"seq_id"}, Values: []interface{}{3}, Keyset: []interface{}{3, 1000}},
+	}, nil
+}
+
+func TestVariantOccurrencesHandlerCursor(t *testing.T) {
+	repo := &carriersNextPageRepository{}
+	router := gin.Default()
+	router.GET("/variants/:locus_id/occurrences", VariantOccurrencesHandler(repo))
+
+	cursor, _ := types.EncodeCursor([]interface{}{1, 1000})
+	req, _ := http.NewRequest("GET", "/variants/1000/occurrences?selected_fields=seq_id&limit=1&cursor="+cursor, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	expectedCursor, _ := types.EncodeCursor([]interface{}{2, 1000})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data": [{"seq_id": 2}], "next_cursor": "`+expectedCursor+`"}`, w.Body.String())
+	assert.Equal(t, []types.SortField{{Field: types.CarrierSeqIdField, Order: "asc"}, {Field: types.LocusIdField, Order: "asc"}}, repo.lastQuery.SortedFields)
+	assert.Equal(t, []interface{}{int64(1), int64(1000)}, repo.lastQuery.Pagination.Cursor.Values)
+}
+
+func TestVariantOccurrencesHandlerVariantField(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.GET("/variants/:locus_id/occurrences", VariantOccurrencesHandler(repo))
+
+	sqon := url.QueryEscape(`{"op": "in", "field": "symbol", "value": ["BRCA1"]}`)
+	req, _ := http.NewRequest("GET", "/variants/1000/occurrences?sqon="+sqon, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "unauthorized or unknown field: symbol"}`, w.Body.String())
+}
+
+func TestVariantOccurrencesHandlerInvalidParameters(t *testing.T) {
+	repo := &MockRepository{}
+	router := gin.Default()
+	router.GET("/variants/:locus_id/occurrences", VariantOccurrencesHandler(repo))
+
+	req, _ := http.NewRequest("GET", "/variants/1000/occurrences?limit=ten", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "invalid limit: ten"}`, w.Body.String())
+
+	req, _ = http.NewRequest("GET", "/variants/1000/occurrences?sqon=%7B", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "invalid sqon: unexpected end of JSON input"}`, w.Body.String())
+
+	req, _ = http.NewRequest("GET", "/variants/1000/occurrences?cursor=invalid", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "invalid cursor: invalid"}`, w.Body.String())
+}
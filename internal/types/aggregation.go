@@ -1,6 +1,66 @@
 package types
 
+const (
+	OrderByCountDesc = "count_desc"
+	OrderByCountAsc  = "count_asc"
+	OrderByKeyAsc    = "key_asc"
+	OrderByKeyDesc   = "key_desc"
+)
+
 type Aggregation struct {
 	Bucket string `json:"key"`
 	Count  int64  `json:"count"`
 }
+
+// TermsOptions defines the buckets returned by a terms aggregation
+type TermsOptions struct {
+	Size    int    // Maximum number of buckets
+	Order   string // Order of the buckets, one of OrderByCountDesc (default), OrderByCountAsc, OrderByKeyAsc, OrderByKeyDesc
+	Missing bool   // Whether to count the rows without value
+}
+
+// TermsAggregation is the result of a terms aggregation
+type TermsAggregation struct {
+	Buckets    []Aggregation `json:"buckets"`           // Top buckets
+	OtherCount int64         `json:"other_count"`       // Number of rows with a value that is not in the top buckets
+	Missing    *int64        `json:"missing,omitempty"` // Number of rows without value, if requested
+}
+
+// HistogramOptions defines the buckets of a histogram aggregation
+type HistogramOptions struct {
+	Interval float64 // Width of the buckets, in powers of 10 when LogScale is set
+	Ranges   []Range // Explicit buckets, Interval and LogScale are ignored when set
+	LogScale bool    // Whether the buckets are computed on the log10 of the values
+}
+
+// Range is an interval of values, From being inclusive and To exclusive
+type Range struct {
+	From *float64 `json:"from,omitempty"` // Unbounded if nil
+	To   *float64 `json:"to,omitempty"`   // Unbounded if nil
+}
+
+type HistogramBucket struct {
+	Range
+	Count int64 `json:"count"`
+}
+
+// Histogram is the result of a histogram aggregation
+type Histogram struct {
+	Buckets   []HistogramBucket `json:"buckets"`              // Buckets ordered by bounds, empty buckets are omitted when using an interval
+	ZeroCount *int64            `json:"zero_count,omitempty"` // Number of values lower or equal to 0 when using a log scale
+}
+
+type Percentile struct {
+	Percentile float64  `json:"percentile"` // Between 0 and 1
+	Value      *float64 `json:"value"`      // Approximate value
+}
+
+// FieldStats is the result of a statistics aggregation on a numeric field, values being nil when the field has no value
+type FieldStats struct {
+	Count       int64        `json:"count"` // Number of values
+	Min         *float64     `json:"min"`
+	Max         *float64     `json:"max"`
+	Mean        *float64     `json:"mean"`
+	Stddev      *float64     `json:"stddev"` // Population standard deviation
+	Percentiles []Percentile `json:"percentiles"`
+}
@@ -0,0 +1,15 @@
+package types
+
+// SampleCount is the number of occurrences of a sequencing experiment of a cohort matching a query
+type SampleCount struct {
+	SeqId int   `json:"seq_id"`
+	Count int64 `json:"count"`
+}
+
+// LocusCarriers gives the number of sequencing experiments of a cohort carrying a variant matching a query
+type LocusCarriers struct {
+	LocusId  int64 `json:"locus_id"`
+	Carriers int64 `json:"carriers"`
+	Het      int64 `json:"het"`
+	Hom      int64 `json:"hom"`
+}
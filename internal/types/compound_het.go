@@ -0,0 +1,124 @@
+package types
+
+const (
+	MaternalOrigin = "maternal"
+	PaternalOrigin = "paternal"
+	UnknownOrigin  = "unknown"
+)
+
+// MaxCompoundHetPairs is the maximum number of candidate pairs returned for a gene, pair_count giving the total
+const MaxCompoundHetPairs = 100
+
+// HetCall is a heterozygous occurrence of a proband along with the zygosity of its parents at the same locus,
+// nil when the parent is not sequenced or has no call
+type HetCall struct {
+	LocusId        int64
+	Symbol         string
+	MotherZygosity interface{}
+	FatherZygosity interface{}
+	Row            Row // Selected fields of the occurrence
+}
+
+// CompoundHetGene is a gene carrying at least one pair of heterozygous occurrences that may be in trans
+type CompoundHetGene struct {
+	Symbol    string     `json:"symbol"`
+	Phased    bool       `json:"phased"` // Whether the pairs are restricted to variants inherited from different parents
+	Variants  []Row      `json:"variants"`
+	Pairs     [][2]int64 `json:"pairs"`      // Locus ids of the first MaxCompoundHetPairs candidate pairs
+	PairCount int        `json:"pair_count"` // Number of candidate pairs
+}
+
+type CompoundHetResponse struct {
+	Data    []CompoundHetGene `json:"data"`
+	Total   int64             `json:"total"`
+	Limit   int               `json:"limit"`
+	Offset  int               `json:"offset"`
+	HasMore bool              `json:"has_more"`
+}
+
+// ParentalOrigin returns the parent from which a heterozygous variant is inherited, given the zygosity of the parents.
+// The origin is unknown when none or both parents carry the variant, or when a call is missing.
+func ParentalOrigin(motherZygosity interface{}, fatherZygosity interface{}) string {
+	mother, father := carries(motherZygosity), carries(fatherZygosity)
+	switch {
+	case mother == nil || father == nil || *mother == *father:
+		return UnknownOrigin
+	case *mother:
+		return MaternalOrigin
+	default:
+		return PaternalOrigin
+	}
+}
+
+// carries returns whether a zygosity has an alternate allele, nil when the zygosity is unknown
+func carries(zygosity interface{}) *bool {
+	var hasAlt bool
+	switch zygosity {
+	case "HET", "HOM":
+		hasAlt = true
+	case "WT":
+		hasAlt = false
+	default:
+		return nil
+	}
+	return &hasAlt
+}
+
+// FindCompoundHets groups the heterozygous calls, ordered by symbol, by gene and returns the genes having candidate pairs.
+// When phased, a pair must have one variant inherited from the mother and the other from the father, otherwise any pair
+// of variants of the gene is a candidate. At most MaxCompoundHetPairs pairs are returned by gene. The origin of each
+// variant is added to its row.
+func FindCompoundHets(calls []HetCall, phased bool) []CompoundHetGene {
+	genes := []CompoundHetGene{}
+	for start := 0; start < len(calls); {
+		end := start + 1
+		for end < len(calls) && calls[end].Symbol == calls[start].Symbol {
+			end++
+		}
+		if gene, ok := findGeneCompoundHets(calls[start:end], phased); ok {
+			genes = append(genes, gene)
+		}
+		start = end
+	}
+	return genes
+}
+
+func findGeneCompoundHets(calls []HetCall, phased bool) (CompoundHetGene, bool) {
+	gene := CompoundHetGene{Symbol: calls[0].Symbol, Phased: phased, Pairs: [][2]int64{}}
+	origins := make([]string, len(calls))
+	counts := map[string]int{}
+	for i, call := range calls {
+		origins[i] = ParentalOrigin(call.MotherZygosity, call.FatherZygosity)
+		counts[origins[i]]++
+	}
+	// Pairs are counted without being enumerated, as their number grows quadratically with the number of variants
+	if phased {
+		gene.PairCount = counts[MaternalOrigin] * counts[PaternalOrigin]
+	} else {
+		gene.PairCount = len(calls) * (len(calls) - 1) / 2
+	}
+	if gene.PairCount == 0 {
+		return gene, false
+	}
+
+	// Every variant with a known origin is part of a pair when phased, as both origins are present
+	var paired []int
+	for i, call := range calls {
+		if !phased || origins[i] != UnknownOrigin {
+			paired = append(paired, i)
+			row := call.Row
+			row.Columns = append(row.Columns[:len(row.Columns):len(row.Columns)], "origin")
+			row.Values = append(row.Values[:len(row.Values):len(row.Values)], origins[i])
+			gene.Variants = append(gene.Variants, row)
+		}
+	}
+	for a := 0; a < len(paired) && len(gene.Pairs) < MaxCompoundHetPairs; a++ {
+		for b := a + 1; b < len(paired) && len(gene.Pairs) < MaxCompoundHetPairs; b++ {
+			i, j := paired[a], paired[b]
+			if !phased || origins[i] != origins[j] {
+				gene.Pairs = append(gene.Pairs, [2]int64{calls[i].LocusId, calls[j].LocusId})
+			}
+		}
+	}
+	return gene, true
+}
@@ -0,0 +1,90 @@
+package types
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func hetCall(locusId int64, symbol string, mother interface{}, father interface{}) HetCall {
+	return HetCall{
+		LocusId:        locusId,
+		Symbol:         symbol,
+		MotherZygosity: mother,
+		FatherZygosity: father,
+		Row:            Row{Columns: []string{"locus_id"}, Values: []interface{}{locusId}},
+	}
+}
+
+func TestParentalOrigin(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, MaternalOrigin, ParentalOrigin("HET", "WT"))
+	assert.Equal(t, PaternalOrigin, ParentalOrigin("WT", "HOM"))
+	assert.Equal(t, UnknownOrigin, ParentalOrigin("HET", "HET"))
+	assert.Equal(t, UnknownOrigin, ParentalOrigin("WT", "WT"))
+	assert.Equal(t, UnknownOrigin, ParentalOrigin("HET", nil))
+	assert.Equal(t, UnknownOrigin, ParentalOrigin("UNK", "WT"))
+}
+
+func TestFindCompoundHetsPhased(t *testing.T) {
+	t.Parallel()
+	calls := []HetCall{
+		hetCall(1, "BRCA1", "HET", "WT"),
+		hetCall(2, "BRCA1", "HET", "WT"),
+		hetCall(3, "BRCA1", "WT", "HET"),
+		hetCall(4, "MYH7", "HET", "WT"),
+		hetCall(5, "MYH7", "HET", "WT"),
+		hetCall(6, "TTN", "HET", "WT"),
+		hetCall(7, "TTN", "HET", nil),
+	}
+	genes := FindCompoundHets(calls, true)
+	if assert.Len(t, genes, 1) {
+		assert.Equal(t, "BRCA1", genes[0].Symbol)
+		assert.True(t, genes[0].Phased)
+		assert.Equal(t, [][2]int64{{1, 3}, {2, 3}}, genes[0].Pairs)
+		assert.Equal(t, 2, genes[0].PairCount)
+		assert.Equal(t, []Row{
+			{Columns: []string{"locus_id", "origin"}, Values: []interface{}{int64(1), MaternalOrigin}},
+			{Columns: []string{"locus_id", "origin"}, Values: []interface{}{int64(2), MaternalOrigin}},
+			{Columns: []string{"locus_id", "origin"}, Values: []interface{}{int64(3), PaternalOrigin}},
+		}, genes[0].Variants)
+	}
+	assert.Equal(t, []string{"locus_id"}, calls[0].Row.Columns)
+}
+
+func TestFindCompoundHetsUnphased(t *testing.T) {
+	t.Parallel()
+	calls := []HetCall{
+		hetCall(1, "BRCA1", nil, nil),
+		hetCall(2, "MYH7", nil, nil),
+		hetCall(3, "MYH7", nil, nil),
+		hetCall(4, "MYH7", nil, nil),
+	}
+	genes := FindCompoundHets(calls, false)
+	if assert.Len(t, genes, 1) {
+		assert.Equal(t, "MYH7", genes[0].Symbol)
+		assert.False(t, genes[0].Phased)
+		assert.Equal(t, [][2]int64{{2, 3}, {2, 4}, {3, 4}}, genes[0].Pairs)
+		assert.Equal(t, 3, genes[0].PairCount)
+		assert.Len(t, genes[0].Variants, 3)
+	}
+}
+
+func TestFindCompoundHetsLimitsPairs(t *testing.T) {
+	t.Parallel()
+	var calls []HetCall
+	for i := int64(0); i < 1000; i++ {
+		calls = append(calls, hetCall(i, "TTN", nil, nil))
+	}
+	genes := FindCompoundHets(calls, false)
+	if assert.Len(t, genes, 1) {
+		assert.Len(t, genes[0].Pairs, MaxCompoundHetPairs)
+		assert.Equal(t, [2]int64{0, 1}, genes[0].Pairs[0])
+		assert.Equal(t, 1000*999/2, genes[0].PairCount)
+		assert.Len(t, genes[0].Variants, 1000)
+	}
+}
+
+func TestFindCompoundHetsEmpty(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, []CompoundHetGene{}, FindCompoundHets(nil, true))
+}
@@ -0,0 +1,112 @@
+package types
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// CursorTiebreaker is the name of the field appended to the sort of a keyset pagination, so that rows are totally ordered
+const CursorTiebreaker = "locus_id"
+
+// Cursor is the position of a keyset pagination
+type Cursor struct {
+	Token  string        // Opaque token given by the client, empty for the first page
+	Values []interface{} // Values of the sorted fields in the last row of the previous page, decoded from Token
+}
+
+// EncodeCursor returns the opaque token of a cursor positioned after a row with the given sorted field values
+func EncodeCursor(values []interface{}) (string, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("error encoding cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor returns the sorted field values of a cursor token, coerced to the type of the fields
+func DecodeCursor(token string, sortedFields []SortField) ([]interface{}, error) {
+	if token == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %s", token)
+	}
+	var values []interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err = decoder.Decode(&values); err != nil || len(values) != len(sortedFields) {
+		return nil, fmt.Errorf("invalid cursor: %s", token)
+	}
+	for i, sort := range sortedFields {
+		if values[i] == nil {
+			continue
+		}
+		if values[i], err = CoerceValue(&sort.Field, "cursor", values[i]); err != nil {
+			return nil, fmt.Errorf("invalid cursor: %s", token)
+		}
+	}
+	return values, nil
+}
+
+// addCursorTiebreaker appends the tiebreaker field to the sort, unless the rows are already sorted by it
+func addCursorTiebreaker(sortedFields []SortField, fields *[]Field) ([]SortField, error) {
+	for _, sort := range sortedFields {
+		if sort.Field.Name == CursorTiebreaker {
+			return sortedFields, nil
+		}
+	}
+	tiebreaker := FindByName(fields, CursorTiebreaker)
+	if tiebreaker == nil {
+		return nil, fmt.Errorf("cursor pagination is not supported")
+	}
+	return append(sortedFields, SortField{Field: *tiebreaker, Order: "asc"}), nil
+}
+
+// KeysetFilterToSQL returns the SQL predicate matching the rows located after the cursor values in the sort order.
+// NULL values are sorted first in ascending order and last in descending order, as StarRocks does.
+func KeysetFilterToSQL(sortedFields []SortField, values []interface{}) (string, []interface{}) {
+	var (
+		disjuncts []string
+		params    []interface{}
+		equals    []string
+		eqParams  []interface{}
+	)
+	for i, sort := range sortedFields {
+		column := sort.Field.QualifiedName()
+		value := values[i]
+
+		var after string
+		var afterParams []interface{}
+		switch {
+		case sort.Order == "desc" && value == nil:
+			after = "" // nothing is sorted after NULL values
+		case sort.Order == "desc":
+			after = fmt.Sprintf("(%s < ? OR %s IS NULL)", column, column)
+			afterParams = []interface{}{value}
+		case value == nil:
+			after = fmt.Sprintf("%s IS NOT NULL", column)
+		default:
+			after = fmt.Sprintf("%s > ?", column)
+			afterParams = []interface{}{value}
+		}
+		if after != "" {
+			disjuncts = append(disjuncts, fmt.Sprintf("(%s)", strings.Join(append(append([]string{}, equals...), after), " AND ")))
+			params = append(append(params, eqParams...), afterParams...)
+		}
+
+		if value == nil {
+			equals = append(equals, fmt.Sprintf("%s IS NULL", column))
+		} else {
+			equals = append(equals, fmt.Sprintf("%s = ?", column))
+			eqParams = append(eqParams, value)
+		}
+	}
+	if len(disjuncts) == 0 {
+		return "1 = 0", nil
+	}
+	return fmt.Sprintf("(%s)", strings.Join(disjuncts, " OR ")), params
+}
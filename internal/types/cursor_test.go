@@ -0,0 +1,108 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var locusIdMetadata = Field{Name: "locus_id", Type: IntType, Table: Table{Alias: "o"}}
+var pfMetadata = Field{Name: "pf", CanBeSorted: true, Type: DecimalType, Table: Table{Alias: "v"}}
+
+var cursorFieldMetadata = []Field{
+	locusIdMetadata,
+	pfMetadata,
+}
+
+func TestEncodeDecodeCursor(t *testing.T) {
+	t.Parallel()
+	sortedFields := []SortField{{Field: pfMetadata, Order: "desc"}, {Field: locusIdMetadata, Order: "asc"}}
+
+	token, err := EncodeCursor([]interface{}{0.25, int64(9007199254740993)})
+	assert.NoError(t, err)
+	values, err := DecodeCursor(token, sortedFields)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{0.25, int64(9007199254740993)}, values)
+}
+
+func TestDecodeCursorWithNull(t *testing.T) {
+	t.Parallel()
+	sortedFields := []SortField{{Field: pfMetadata, Order: "desc"}, {Field: locusIdMetadata, Order: "asc"}}
+
+	token, _ := EncodeCursor([]interface{}{nil, int64(1000)})
+	values, err := DecodeCursor(token, sortedFields)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{nil, int64(1000)}, values)
+}
+
+func TestDecodeEmptyCursor(t *testing.T) {
+	t.Parallel()
+	values, err := DecodeCursor("", []SortField{{Field: locusIdMetadata, Order: "asc"}})
+	assert.NoError(t, err)
+	assert.Nil(t, values)
+}
+
+func TestDecodeInvalidCursor(t *testing.T) {
+	t.Parallel()
+	sortedFields := []SortField{{Field: locusIdMetadata, Order: "asc"}}
+
+	_, err := DecodeCursor("not a cursor!", sortedFields)
+	assert.ErrorContains(t, err, "invalid cursor")
+
+	token, _ := EncodeCursor([]interface{}{0.25, int64(1000)})
+	_, err = DecodeCursor(token, sortedFields)
+	assert.ErrorContains(t, err, "invalid cursor")
+
+	token, _ = EncodeCursor([]interface{}{"abc"})
+	_, err = DecodeCursor(token, sortedFields)
+	assert.ErrorContains(t, err, "invalid cursor")
+}
+
+func TestKeysetFilterToSQL(t *testing.T) {
+	t.Parallel()
+	sortedFields := []SortField{{Field: pfMetadata, Order: "desc"}, {Field: locusIdMetadata, Order: "asc"}}
+
+	sqlQuery, params := KeysetFilterToSQL(sortedFields, []interface{}{0.25, int64(1000)})
+
+	assert.Equal(t, `(((v.pf < ? OR v.pf IS NULL)) OR (v.pf = ? AND o.locus_id > ?))`, sqlQuery)
+	assert.Equal(t, []interface{}{0.25, 0.25, int64(1000)}, params)
+}
+
+func TestKeysetFilterToSQLWithNullValues(t *testing.T) {
+	t.Parallel()
+	sortedFields := []SortField{{Field: pfMetadata, Order: "desc"}, {Field: locusIdMetadata, Order: "asc"}}
+
+	sqlQuery, params := KeysetFilterToSQL(sortedFields, []interface{}{nil, int64(1000)})
+
+	assert.Equal(t, `((v.pf IS NULL AND o.locus_id > ?))`, sqlQuery)
+	assert.Equal(t, []interface{}{int64(1000)}, params)
+}
+
+func TestKeysetFilterToSQLWithNullAscending(t *testing.T) {
+	t.Parallel()
+	sortedFields := []SortField{{Field: pfMetadata, Order: "asc"}, {Field: locusIdMetadata, Order: "asc"}}
+
+	sqlQuery, params := KeysetFilterToSQL(sortedFields, []interface{}{nil, int64(1000)})
+
+	assert.Equal(t, `((v.pf IS NOT NULL) OR (v.pf IS NULL AND o.locus_id > ?))`, sqlQuery)
+	assert.Equal(t, []interface{}{int64(1000)}, params)
+}
+
+func TestBuildQueryWithCursorAddsTiebreaker(t *testing.T) {
+	t.Parallel()
+	token, _ := EncodeCursor([]interface{}{0.25, float64(1000)})
+	pagination := Pagination{Limit: 10, Cursor: &Cursor{Token: token}}
+
+	query, err := BuildQuery(nil, nil, &cursorFieldMetadata, &pagination, []SortBody{{Field: "pf", Order: "desc"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []SortField{{Field: pfMetadata, Order: "desc"}, {Field: locusIdMetadata, Order: "asc"}}, query.SortedFields)
+	assert.Equal(t, []interface{}{0.25, int64(1000)}, query.Pagination.Cursor.Values)
+}
+
+func TestBuildQueryWithCursorWithoutTiebreaker(t *testing.T) {
+	t.Parallel()
+	pagination := Pagination{Limit: 10, Cursor: &Cursor{}}
+
+	_, err := BuildQuery(nil, nil, &fieldMetadata, &pagination, nil)
+	assert.ErrorContains(t, err, "cursor pagination is not supported")
+}
@@ -0,0 +1,82 @@
+package types
+
+// Family gives the sequencing experiments of the parents of a proband, nil when a parent is not sequenced
+type Family struct {
+	ProbandSeqId int  `json:"proband_seq_id"`
+	MotherSeqId  *int `json:"mother_seq_id"`
+	FatherSeqId  *int `json:"father_seq_id"`
+}
+
+var MotherOccurrenceTable = Table{
+	Name:  "occurrences",
+	Alias: "mo",
+}
+
+var FatherOccurrenceTable = Table{
+	Name:  "occurrences",
+	Alias: "fa",
+}
+
+var MotherZygosityField = Field{
+	Name:          "zygosity",
+	Alias:         "mother_zygosity",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         MotherOccurrenceTable,
+	Type:          EnumType,
+	EnumValues:    &ZygosityValues,
+}
+var MotherAdRatioField = Field{
+	Name:          "ad_ratio",
+	Alias:         "mother_ad_ratio",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         MotherOccurrenceTable,
+	Type:          DecimalType,
+}
+var MotherGqField = Field{
+	Name:          "gq",
+	Alias:         "mother_gq",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         MotherOccurrenceTable,
+	Type:          IntType,
+}
+var FatherZygosityField = Field{
+	Name:          "zygosity",
+	Alias:         "father_zygosity",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         FatherOccurrenceTable,
+	Type:          EnumType,
+	EnumValues:    &ZygosityValues,
+}
+var FatherAdRatioField = Field{
+	Name:          "ad_ratio",
+	Alias:         "father_ad_ratio",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         FatherOccurrenceTable,
+	Type:          DecimalType,
+}
+var FatherGqField = Field{
+	Name:          "gq",
+	Alias:         "father_gq",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         FatherOccurrenceTable,
+	Type:          IntType,
+}
+
+// ParentFields are the fields of the occurrences of the parents at the locus of a proband occurrence
+var ParentFields = []Field{
+	MotherZygosityField,
+	MotherAdRatioField,
+	MotherGqField,
+	FatherZygosityField,
+	FatherAdRatioField,
+	FatherGqField,
+}
+
+// FamilyOccurrencesFields are the fields of the occurrences of a proband along with the ones of its parents
+var FamilyOccurrencesFields = append(OccurrencesFields[:len(OccurrencesFields):len(OccurrencesFields)], ParentFields...)
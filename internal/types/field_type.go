@@ -0,0 +1,111 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Goldziher/go-utils/sliceutils"
+)
+
+// CoerceValue converts a SQON value, either a single value or an array of values, to the type of the field.
+// It returns an error naming the field, the operation and the expected type if a value does not match.
+func CoerceValue(field *Field, op string, value interface{}) (interface{}, error) {
+	if field.Type == "" {
+		return value, nil
+	}
+	if values, ok := value.([]interface{}); ok {
+		coerced := make([]interface{}, len(values))
+		for i, v := range values {
+			c, err := CoerceValue(field, op, v)
+			if err != nil {
+				return nil, err
+			}
+			coerced[i] = c
+		}
+		return coerced, nil
+	}
+	coerced, ok := coerceScalar(field, value)
+	if !ok {
+		return nil, fmt.Errorf("invalid value %v for field %s with operation %s: expected %s", value, field.GetAlias(), op, field.ExpectedType())
+	}
+	return coerced, nil
+}
+
+// ExpectedType returns a human-readable description of the values accepted by the field
+func (f *Field) ExpectedType() string {
+	var expected string
+	if f.Type == EnumType && f.EnumValues != nil {
+		expected = fmt.Sprintf("one of [%s]", strings.Join(*f.EnumValues, ", "))
+	} else if f.Type == DateType {
+		expected = "date formatted as YYYY-MM-DD"
+	} else {
+		expected = string(f.Type)
+	}
+	if f.IsArray {
+		return fmt.Sprintf("array of %s", expected)
+	}
+	return expected
+}
+
+// IsNumeric returns whether the field holds a single number
+func (f *Field) IsNumeric() bool {
+	return (f.Type == IntType || f.Type == DecimalType) && !f.IsArray
+}
+
+func coerceScalar(field *Field, value interface{}) (interface{}, bool) {
+	switch field.Type {
+	case IntType:
+		switch v := value.(type) {
+		case float64:
+			// float64(math.MaxInt64) rounds up to 2^63, so the upper bound is exclusive
+			if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+				return nil, false
+			}
+			return int64(v), true
+		case int:
+			return int64(v), true
+		case int32:
+			return int64(v), true
+		case int64:
+			return v, true
+		case json.Number:
+			i, err := v.Int64()
+			return i, err == nil
+		}
+	case DecimalType:
+		switch v := value.(type) {
+		case float64:
+			return v, true
+		case float32:
+			return float64(v), true
+		case int:
+			return float64(v), true
+		case int64:
+			return float64(v), true
+		case json.Number:
+			f, err := v.Float64()
+			return f, err == nil
+		}
+	case StringType:
+		if v, ok := value.(string); ok {
+			return v, true
+		}
+	case BoolType:
+		if v, ok := value.(bool); ok {
+			return v, true
+		}
+	case DateType:
+		if v, ok := value.(string); ok {
+			_, err := time.Parse(time.DateOnly, v)
+			return v, err == nil
+		}
+	case EnumType:
+		if v, ok := value.(string); ok && (field.EnumValues == nil || sliceutils.Includes(*field.EnumValues, v)) {
+			return v, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,150 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var statusValues = []string{"active", "inactive"}
+var countMetadata = Field{Name: "count", CanBeFiltered: true, Type: IntType}
+var ratioMetadata = Field{Name: "ratio", CanBeFiltered: true, Type: DecimalType}
+var nameMetadata = Field{Name: "name", CanBeFiltered: true, Type: StringType}
+var enabledMetadata = Field{Name: "enabled", CanBeFiltered: true, Type: BoolType}
+var statusMetadata = Field{Name: "status", CanBeFiltered: true, Type: EnumType, EnumValues: &statusValues}
+var tagsMetadata = Field{Name: "tags", CanBeFiltered: true, Type: StringType, IsArray: true, CustomOp: ArrayOperators}
+
+var typedFieldMetadata = []Field{
+	countMetadata,
+	ratioMetadata,
+	nameMetadata,
+	enabledMetadata,
+	statusMetadata,
+	tagsMetadata,
+}
+
+func TestCoerceValueIntFromJSONNumber(t *testing.T) {
+	t.Parallel()
+	value, err := CoerceValue(&countMetadata, "in", []interface{}{float64(10), float64(20)})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{int64(10), int64(20)}, value)
+}
+
+func TestCoerceValueIntRejectsFraction(t *testing.T) {
+	t.Parallel()
+	_, err := CoerceValue(&countMetadata, ">", 10.5)
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "invalid value 10.5 for field count with operation >: expected int")
+}
+
+func TestCoerceValueIntRejectsOutOfRange(t *testing.T) {
+	t.Parallel()
+	_, err := CoerceValue(&countMetadata, ">", 1e300)
+	assert.ErrorContains(t, err, "invalid value 1e+300 for field count with operation >: expected int")
+
+	_, err = CoerceValue(&countMetadata, "<", -1e300)
+	assert.Error(t, err)
+
+	_, err = CoerceValue(&countMetadata, ">", json.Number("99999999999999999999"))
+	assert.Error(t, err)
+}
+
+func TestCoerceValueDecimalFromInt(t *testing.T) {
+	t.Parallel()
+	value, err := CoerceValue(&ratioMetadata, ">=", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, value)
+}
+
+func TestCoerceValueDecimalRejectsString(t *testing.T) {
+	t.Parallel()
+	_, err := CoerceValue(&ratioMetadata, ">=", "0.5")
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "invalid value 0.5 for field ratio with operation >=: expected decimal")
+}
+
+func TestCoerceValueStringRejectsNumber(t *testing.T) {
+	t.Parallel()
+	_, err := CoerceValue(&nameMetadata, "in", []interface{}{"a", float64(1)})
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "invalid value 1 for field name with operation in: expected string")
+}
+
+func TestCoerceValueBool(t *testing.T) {
+	t.Parallel()
+	value, err := CoerceValue(&enabledMetadata, "in", true)
+	assert.NoError(t, err)
+	assert.Equal(t, true, value)
+
+	_, err = CoerceValue(&enabledMetadata, "in", "true")
+	assert.ErrorContains(t, err, "expected bool")
+}
+
+func TestCoerceValueEnum(t *testing.T) {
+	t.Parallel()
+	value, err := CoerceValue(&statusMetadata, "in", []interface{}{"active"})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"active"}, value)
+
+	_, err = CoerceValue(&statusMetadata, "in", []interface{}{"deleted"})
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "invalid value deleted for field status with operation in: expected one of [active, inactive]")
+}
+
+func TestCoerceValueDate(t *testing.T) {
+	t.Parallel()
+	dateMetadata := Field{Name: "date", CanBeFiltered: true, Type: DateType}
+	value, err := CoerceValue(&dateMetadata, ">", "2024-02-29")
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-02-29", value)
+
+	_, err = CoerceValue(&dateMetadata, ">", "2023-02-29")
+	assert.EqualError(t, err, "invalid value 2023-02-29 for field date with operation >: expected date formatted as YYYY-MM-DD")
+}
+
+func TestCoerceValueArray(t *testing.T) {
+	t.Parallel()
+	_, err := CoerceValue(&tagsMetadata, "all", []interface{}{"a", true})
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "invalid value true for field tags with operation all: expected array of string")
+}
+
+func TestCoerceValueUntypedField(t *testing.T) {
+	t.Parallel()
+	value, err := CoerceValue(&ageMetadata, "in", "thirty")
+	assert.NoError(t, err)
+	assert.Equal(t, "thirty", value)
+}
+
+func TestParseSQONCoercesValues(t *testing.T) {
+	t.Parallel()
+	sqon := SQON{
+		Op: "and",
+		Content: []SQON{
+			{Op: "between", Field: "count", Value: []interface{}{float64(1), float64(5)}},
+			{Op: "in", Field: "status", Value: "active"},
+		},
+	}
+
+	ast, _, err := parseSQONToAST(&sqon, &typedFieldMetadata)
+	if assert.NoError(t, err) {
+		_, params := ast.ToSQL()
+		assert.Equal(t, []interface{}{int64(1), int64(5), "active"}, params)
+	}
+}
+
+func TestParseSQONRejectsMismatchedType(t *testing.T) {
+	t.Parallel()
+	sqon := SQON{
+		Op: "or",
+		Content: []SQON{
+			{Op: "in", Field: "name", Value: "john"},
+			{Op: ">", Field: "ratio", Value: "high"},
+		},
+	}
+
+	_, _, err := parseSQONToAST(&sqon, &typedFieldMetadata)
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "invalid value high for field ratio with operation >: expected decimal")
+}
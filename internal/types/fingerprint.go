@@ -0,0 +1,86 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Goldziher/go-utils/sliceutils"
+)
+
+// Fingerprint returns a hash of the query which is the same for equivalent queries: the children of "and" and "or"
+// nodes and the values of multi-valued comparisons are compared regardless of their order
+func (q *Query) Fingerprint() string {
+	var b strings.Builder
+	b.WriteString("filters:")
+	if q.Filters != nil {
+		b.WriteString(canonicalFilter(q.Filters))
+	}
+	b.WriteString("|selected:")
+	b.WriteString(strings.Join(sliceutils.Map(q.SelectedFields, func(field Field, index int, slice []Field) string {
+		return canonicalField(field)
+	}), ","))
+	b.WriteString("|sort:")
+	b.WriteString(strings.Join(sliceutils.Map(q.SortedFields, func(sort SortField, index int, slice []SortField) string {
+		return fmt.Sprintf("%s %s", canonicalField(sort.Field), sort.Order)
+	}), ","))
+	if p := q.Pagination; p != nil {
+		fmt.Fprintf(&b, "|limit:%d|offset:%d", p.Limit, p.Offset)
+		if p.Cursor != nil {
+			fmt.Fprintf(&b, "|cursor:%s", p.Cursor.Token)
+		}
+	}
+	hash := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(hash[:])
+}
+
+// canonicalField identifies a field by its alias and the column or expression it is computed from
+func canonicalField(field Field) string {
+	return fmt.Sprintf("%s=%s", field.GetAlias(), field.QualifiedName())
+}
+
+// canonicalFilter returns a representation of the node which does not depend on the order of commutative operands
+func canonicalFilter(node FilterNode) string {
+	switch n := node.(type) {
+	case *AndNode:
+		return canonicalChildren("and", n.Children)
+	case *OrNode:
+		return canonicalChildren("or", n.Children)
+	case *NotNode:
+		return fmt.Sprintf("not(%s)", canonicalFilter(n.Child))
+	case *ComparisonNode:
+		return fmt.Sprintf("%s(%s,%s)", n.Operator, canonicalField(n.Field), canonicalValue(n.Operator, n.Value))
+	default:
+		sql, params := node.ToSQL()
+		encoded, _ := json.Marshal(params)
+		return fmt.Sprintf("sql(%s,%s)", sql, encoded)
+	}
+}
+
+func canonicalChildren(op string, children []FilterNode) string {
+	parts := sliceutils.Map(children, func(child FilterNode, index int, slice []FilterNode) string {
+		return canonicalFilter(child)
+	})
+	slices.Sort(parts)
+	return fmt.Sprintf("%s(%s)", op, strings.Join(parts, ","))
+}
+
+// canonicalValue encodes the value of a comparison, the values of set operators being sorted
+func canonicalValue(op string, value interface{}) string {
+	values, ok := value.([]interface{})
+	if !ok {
+		encoded, _ := json.Marshal(value)
+		return string(encoded)
+	}
+	parts := sliceutils.Map(values, func(v interface{}, index int, slice []interface{}) string {
+		encoded, _ := json.Marshal(v)
+		return string(encoded)
+	})
+	if op != "between" {
+		slices.Sort(parts)
+	}
+	return fmt.Sprintf("[%s]", strings.Join(parts, ","))
+}
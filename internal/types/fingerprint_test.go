@@ -0,0 +1,50 @@
+package types
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func fingerprint(t *testing.T, sqon *SQON, pagination *Pagination) string {
+	query, err := BuildQuery([]string{"locus_id", "ad_ratio"}, sqon, &OccurrencesFields, pagination, nil)
+	assert.NoError(t, err)
+	return query.Fingerprint()
+}
+
+func TestFingerprintIgnoresOrderOfClausesAndValues(t *testing.T) {
+	t.Parallel()
+	first := &SQON{Op: "and", Content: []SQON{
+		{Op: "in", Field: "zygosity", Value: []interface{}{"HET", "HOM"}},
+		{Op: ">=", Field: "gq", Value: 20},
+	}}
+	second := &SQON{Op: "and", Content: []SQON{
+		{Op: ">=", Field: "gq", Value: 20},
+		{Op: "in", Field: "zygosity", Value: []interface{}{"HOM", "HET"}},
+	}}
+	assert.Equal(t, fingerprint(t, first, nil), fingerprint(t, second, nil))
+}
+
+func TestFingerprintDistinguishesQueries(t *testing.T) {
+	t.Parallel()
+	het := &SQON{Op: "in", Field: "zygosity", Value: []interface{}{"HET"}}
+	hom := &SQON{Op: "in", Field: "zygosity", Value: []interface{}{"HOM"}}
+	notHet := &SQON{Op: "not", Content: []SQON{*het}}
+	assert.NotEqual(t, fingerprint(t, het, nil), fingerprint(t, hom, nil))
+	assert.NotEqual(t, fingerprint(t, het, nil), fingerprint(t, notHet, nil))
+	assert.NotEqual(t, fingerprint(t, het, nil), fingerprint(t, nil, nil))
+	assert.NotEqual(t, fingerprint(t, het, &Pagination{Limit: 10}), fingerprint(t, het, &Pagination{Limit: 10, Offset: 10}))
+
+	between := &SQON{Op: "between", Field: "ad_ratio", Value: []interface{}{0.2, 0.8}}
+	reversed := &SQON{Op: "between", Field: "ad_ratio", Value: []interface{}{0.8, 0.2}}
+	assert.NotEqual(t, fingerprint(t, between, nil), fingerprint(t, reversed, nil))
+}
+
+func TestFingerprintDistinguishesDerivedFields(t *testing.T) {
+	t.Parallel()
+	minGq := 30
+	strict, _ := InheritanceField(InheritanceThresholds{MinGq: &minGq})
+	lenient, _ := InheritanceField(InheritanceThresholds{})
+	first := Query{SelectedFields: []Field{strict}}
+	second := Query{SelectedFields: []Field{lenient}}
+	assert.NotEqual(t, first.Fingerprint(), second.Fingerprint())
+}
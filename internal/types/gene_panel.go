@@ -0,0 +1,190 @@
+package types
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Goldziher/go-utils/sliceutils"
+)
+
+// GenePanelFieldName is the name of the virtual field used to filter on gene panels, e.g. {"op":"in","field":"panel","value":["cardiomyopathy_v2"]}
+const GenePanelFieldName = "panel"
+
+// GenePanel is a named list of genes, with optional regions
+type GenePanel struct {
+	Name    string   `json:"name"`
+	Symbols []string `json:"symbols"`
+	Regions []string `json:"regions,omitempty"`
+}
+
+var genePanels = struct {
+	sync.RWMutex
+	byName map[string]GenePanel
+}{byName: map[string]GenePanel{}}
+
+// RegisterGenePanels makes the panels available to SQON filters, replacing any panel with the same name
+func RegisterGenePanels(panels []GenePanel) {
+	genePanels.Lock()
+	defer genePanels.Unlock()
+	for _, panel := range panels {
+		genePanels.byName[panel.Name] = panel
+	}
+}
+
+// FindGenePanel returns the registered panel with the given name, or nil if it does not exist
+func FindGenePanel(name string) *GenePanel {
+	genePanels.RLock()
+	defer genePanels.RUnlock()
+	panel, ok := genePanels.byName[name]
+	if !ok {
+		return nil
+	}
+	return &panel
+}
+
+// LoadGenePanels reads the panels of a directory. Each .json file contains a GenePanel. Each .tsv file contains a
+// panel named after the file, with a header line, a "symbol" column and an optional "region" column.
+func LoadGenePanels(dir string) ([]GenePanel, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading gene panels directory: %w", err)
+	}
+	var panels []GenePanel
+	for _, file := range files {
+		path := filepath.Join(dir, file.Name())
+		var panel GenePanel
+		switch filepath.Ext(file.Name()) {
+		case ".json":
+			panel, err = loadJSONGenePanel(path)
+		case ".tsv":
+			panel, err = loadTSVGenePanel(path)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if panel.Name == "" {
+			panel.Name = strings.TrimSuffix(file.Name(), filepath.Ext(file.Name()))
+		}
+		for _, region := range panel.Regions {
+			if _, err := ParseRegion(region); err != nil {
+				return nil, fmt.Errorf("error in gene panel %s: %w", panel.Name, err)
+			}
+		}
+		panels = append(panels, panel)
+	}
+	return panels, nil
+}
+
+func loadJSONGenePanel(path string) (GenePanel, error) {
+	var panel GenePanel
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return panel, fmt.Errorf("error reading gene panel %s: %w", path, err)
+	}
+	if err = json.Unmarshal(content, &panel); err != nil {
+		return panel, fmt.Errorf("error parsing gene panel %s: %w", path, err)
+	}
+	return panel, nil
+}
+
+func loadTSVGenePanel(path string) (GenePanel, error) {
+	var panel GenePanel
+	file, err := os.Open(path)
+	if err != nil {
+		return panel, fmt.Errorf("error reading gene panel %s: %w", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() {
+		return panel, fmt.Errorf("error parsing gene panel %s: missing header", path)
+	}
+	header := strings.Split(scanner.Text(), "\t")
+	symbolIndex := sliceutils.FindIndexOf(header, "symbol")
+	regionIndex := sliceutils.FindIndexOf(header, "region")
+	if symbolIndex < 0 {
+		return panel, fmt.Errorf("error parsing gene panel %s: missing symbol column", path)
+	}
+	for scanner.Scan() {
+		values := strings.Split(scanner.Text(), "\t")
+		if symbolIndex < len(values) && values[symbolIndex] != "" {
+			panel.Symbols = append(panel.Symbols, values[symbolIndex])
+		}
+		if regionIndex >= 0 && regionIndex < len(values) && values[regionIndex] != "" {
+			panel.Regions = append(panel.Regions, values[regionIndex])
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return panel, fmt.Errorf("error reading gene panel %s: %w", path, err)
+	}
+	return panel, nil
+}
+
+// parseGenePanelSQON expands an "in" or "not-in" SQON on gene panels to the symbols and regions of the panels
+func parseGenePanelSQON(sqon *SQON, fields *[]Field) (FilterNode, []Field, error) {
+	if sqon.Op != "in" && sqon.Op != "not-in" {
+		return nil, nil, fmt.Errorf("operation %s is not supported for field: %s", sqon.Op, sqon.Field)
+	}
+	symbolField := FindByName(fields, "symbol")
+	if symbolField == nil || !symbolField.CanBeFiltered {
+		return nil, nil, fmt.Errorf("unauthorized or unknown field: %s", sqon.Field)
+	}
+	names, ok := sqon.Value.([]interface{})
+	if !ok {
+		names = []interface{}{sqon.Value}
+	}
+
+	var symbols []interface{}
+	var regions []interface{}
+	for _, n := range names {
+		name, ok := n.(string)
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid value %v for field %s with operation %s: expected string", n, sqon.Field, sqon.Op)
+		}
+		panel := FindGenePanel(name)
+		if panel == nil {
+			return nil, nil, fmt.Errorf("unknown gene panel: %s", name)
+		}
+		for _, symbol := range panel.Symbols {
+			symbols = append(symbols, symbol)
+		}
+		for _, region := range panel.Regions {
+			regions = append(regions, region)
+		}
+	}
+
+	var children []FilterNode
+	visitedFields := []Field{*symbolField}
+	if len(symbols) > 0 {
+		children = append(children, &ComparisonNode{Operator: sqon.Op, Value: sliceutils.Unique(symbols), Field: *symbolField})
+	}
+	if len(regions) > 0 {
+		regionNode, regionFields, err := parseRegionSQON(&SQON{Op: "region", Value: regions}, fields)
+		if err != nil {
+			return nil, nil, err
+		}
+		if sqon.Op == "not-in" {
+			regionNode = &NotNode{Child: regionNode}
+		}
+		children = append(children, regionNode)
+		visitedFields = sliceutils.Unique(append(visitedFields, regionFields...))
+	}
+
+	switch {
+	case len(children) == 0:
+		return nil, nil, fmt.Errorf("gene panel must contain at least one symbol or region: %v", sqon.Value)
+	case len(children) == 1:
+		return children[0], visitedFields, nil
+	case sqon.Op == "not-in":
+		return &AndNode{Children: children}, visitedFields, nil
+	default:
+		return &OrNode{Children: children}, visitedFields, nil
+	}
+}
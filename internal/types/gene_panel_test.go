@@ -0,0 +1,93 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var symbolMetadata = Field{Name: "symbol", CanBeFiltered: true, Type: StringType, Table: Table{Alias: "v"}}
+
+var geneFieldMetadata = []Field{
+	symbolMetadata,
+	chromosomeMetadata,
+	startMetadata,
+}
+
+func TestLoadGenePanels(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	json := `{"name": "cardiomyopathy_v2", "symbols": ["MYH7", "TTN"], "regions": ["chr1:100-200"]}`
+	tsv := "symbol\tregion\nBRCA1\tchr17:43044295-43125483\nBRCA2\t\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "cardio.json"), []byte(json), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "hboc.tsv"), []byte(tsv), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("ignored"), 0644))
+
+	panels, err := LoadGenePanels(dir)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []GenePanel{
+		{Name: "cardiomyopathy_v2", Symbols: []string{"MYH7", "TTN"}, Regions: []string{"chr1:100-200"}},
+		{Name: "hboc", Symbols: []string{"BRCA1", "BRCA2"}, Regions: []string{"chr17:43044295-43125483"}},
+	}, panels)
+}
+
+func TestLoadGenePanelsInvalidRegion(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "bad.tsv"), []byte("symbol\tregion\nBRCA1\tchr99:1-2\n"), 0644))
+
+	_, err := LoadGenePanels(dir)
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "error in gene panel bad")
+}
+
+func TestParseSQONGenePanel(t *testing.T) {
+	t.Parallel()
+	RegisterGenePanels([]GenePanel{{Name: "test_panel_in", Symbols: []string{"MYH7", "TTN"}, Regions: []string{"chr1:100-200"}}})
+	sqon := SQON{Op: "in", Field: "panel", Value: []interface{}{"test_panel_in"}}
+
+	ast, visitedFields, err := parseSQONToAST(&sqon, &geneFieldMetadata)
+	if assert.NoError(t, err) {
+		assert.ElementsMatch(t, geneFieldMetadata, visitedFields)
+		sqlQuery, params := ast.ToSQL()
+		assert.Equal(t, `(v.symbol IN (?, ?) OR ((o.chromosome = ? AND o.start BETWEEN ? AND ?)))`, sqlQuery)
+		assert.Equal(t, []interface{}{"MYH7", "TTN", "1", int64(100), int64(200)}, params)
+	}
+}
+
+func TestParseSQONGenePanelNotIn(t *testing.T) {
+	t.Parallel()
+	RegisterGenePanels([]GenePanel{
+		{Name: "test_panel_not_in_1", Symbols: []string{"MYH7"}},
+		{Name: "test_panel_not_in_2", Symbols: []string{"MYH7", "TTN"}},
+	})
+	sqon := SQON{Op: "not-in", Field: "panel", Value: []interface{}{"test_panel_not_in_1", "test_panel_not_in_2"}}
+
+	ast, visitedFields, err := parseSQONToAST(&sqon, &geneFieldMetadata)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []Field{symbolMetadata}, visitedFields)
+		sqlQuery, params := ast.ToSQL()
+		assert.Equal(t, `(v.symbol NOT IN (?, ?) OR v.symbol IS NULL)`, sqlQuery)
+		assert.Equal(t, []interface{}{"MYH7", "TTN"}, params)
+	}
+}
+
+func TestParseSQONUnknownGenePanel(t *testing.T) {
+	t.Parallel()
+	sqon := SQON{Op: "in", Field: "panel", Value: "unknown_panel"}
+
+	_, _, err := parseSQONToAST(&sqon, &geneFieldMetadata)
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "unknown gene panel: unknown_panel")
+}
+
+func TestParseSQONGenePanelWithoutSymbolField(t *testing.T) {
+	t.Parallel()
+	sqon := SQON{Op: "in", Field: "panel", Value: "unknown_panel"}
+
+	_, _, err := parseSQONToAST(&sqon, &fieldMetadata)
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "unauthorized or unknown field: panel")
+}
@@ -0,0 +1,75 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	DeNovo             = "de_novo"
+	AutosomalRecessive = "autosomal_recessive"
+	XLinkedHemizygous  = "x_linked_hemizygous"
+)
+
+const (
+	DefaultInheritanceMinGq = 20
+	DefaultInheritanceMinDp = 10
+)
+
+var InheritanceValues = []string{DeNovo, AutosomalRecessive, XLinkedHemizygous}
+
+// autosomes are the chromosomes 1 to 22, excluding the sex and mitochondrial chromosomes
+var autosomes = chromosomes[:22]
+
+// InheritanceThresholds are the minimum genotype quality and depth of the calls of a trio for a proband occurrence
+// to be classified, defaults are used when not set
+type InheritanceThresholds struct {
+	MinGq *int `json:"min_gq"`
+	MinDp *int `json:"min_dp"`
+}
+
+// InheritanceField returns the derived field classifying the occurrences of a proband by mode of inheritance from the
+// calls of its parents at the same locus. It is NULL when no mode matches or when a call of the trio is missing or
+// below the thresholds.
+func InheritanceField(thresholds InheritanceThresholds) (Field, error) {
+	minGq, minDp := DefaultInheritanceMinGq, DefaultInheritanceMinDp
+	if thresholds.MinGq != nil {
+		minGq = *thresholds.MinGq
+	}
+	if thresholds.MinDp != nil {
+		minDp = *thresholds.MinDp
+	}
+	if minGq < 0 {
+		return Field{}, fmt.Errorf("invalid min_gq %d: must not be negative", minGq)
+	}
+	if minDp < 0 {
+		return Field{}, fmt.Errorf("invalid min_dp %d: must not be negative", minDp)
+	}
+
+	autosome := fmt.Sprintf("o.chromosome IN ('%s')", strings.Join(autosomes, "', '"))
+	quality := fmt.Sprintf("o.gq >= %[1]d AND o.dp >= %[2]d AND mo.gq >= %[1]d AND mo.dp >= %[2]d AND fa.gq >= %[1]d AND fa.dp >= %[2]d", minGq, minDp)
+	expression := fmt.Sprintf("CASE WHEN %s THEN CASE"+
+		" WHEN o.zygosity = 'HET' AND mo.zygosity = 'WT' AND fa.zygosity = 'WT' THEN '%s'"+
+		" WHEN o.zygosity = 'HOM' AND o.chromosome = 'X' AND mo.zygosity = 'HET' AND fa.zygosity = 'WT' THEN '%s'"+
+		" WHEN o.zygosity = 'HOM' AND %s AND mo.zygosity = 'HET' AND fa.zygosity = 'HET' THEN '%s'"+
+		" END END", quality, DeNovo, XLinkedHemizygous, autosome, AutosomalRecessive)
+
+	return Field{
+		Name:          "inheritance",
+		CanBeSelected: true,
+		CanBeFiltered: true,
+		Table:         OccurrenceTable,
+		Type:          EnumType,
+		EnumValues:    &InheritanceValues,
+		Expression:    expression,
+	}, nil
+}
+
+// FamilyFields returns the fields of the family occurrences along with the inheritance field classifying with the thresholds
+func FamilyFields(thresholds InheritanceThresholds) ([]Field, error) {
+	inheritance, err := InheritanceField(thresholds)
+	if err != nil {
+		return nil, err
+	}
+	return append(FamilyOccurrencesFields[:len(FamilyOccurrencesFields):len(FamilyOccurrencesFields)], inheritance), nil
+}
@@ -0,0 +1,46 @@
+package types
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestInheritanceFieldDefaultThresholds(t *testing.T) {
+	t.Parallel()
+
+	field, err := InheritanceField(InheritanceThresholds{})
+	assert.NoError(t, err)
+	assert.Equal(t, "inheritance", field.GetAlias())
+	assert.Contains(t, field.Expression, "o.gq >= 20 AND o.dp >= 10 AND mo.gq >= 20 AND mo.dp >= 10 AND fa.gq >= 20 AND fa.dp >= 10")
+	assert.Equal(t, "("+field.Expression+")", field.QualifiedName())
+}
+
+func TestInheritanceFieldAutosomalRecessiveOnAutosomes(t *testing.T) {
+	t.Parallel()
+
+	field, err := InheritanceField(InheritanceThresholds{})
+	assert.NoError(t, err)
+	assert.Contains(t, field.Expression, "o.chromosome IN ('1', '2', '3', '4', '5', '6', '7', '8', '9', '10', '11', '12', "+
+		"'13', '14', '15', '16', '17', '18', '19', '20', '21', '22') AND mo.zygosity = 'HET' AND fa.zygosity = 'HET'")
+}
+
+func TestInheritanceFieldInvalidThreshold(t *testing.T) {
+	t.Parallel()
+
+	minDp := -5
+	_, err := InheritanceField(InheritanceThresholds{MinDp: &minDp})
+	assert.EqualError(t, err, "invalid min_dp -5: must not be negative")
+}
+
+func TestFamilyFieldsFindInheritance(t *testing.T) {
+	t.Parallel()
+
+	fields, err := FamilyFields(InheritanceThresholds{})
+	assert.NoError(t, err)
+	assert.Len(t, fields, len(FamilyOccurrencesFields)+1)
+	field := FindByName(&fields, "inheritance")
+	if assert.NotNil(t, field) {
+		assert.True(t, field.CanBeFiltered)
+		assert.False(t, field.CanBeSorted)
+	}
+}
@@ -1,60 +1,49 @@
 package types
 
-type Occurrence struct {
-	SeqId                 int     `json:"seq_id,omitempty"`
-	Chromosome            string  `json:"chromosome,omitempty"`
-	LocusId               int64   `json:"locus_id,omitempty"`
-	Quality               int     `json:"quality,omitempty"`
-	Filter                string  `json:"filter,omitempty"`
-	Zygosity              string  `json:"zygosity,omitempty"`
-	Pf                    float64 `json:"pf,omitempty"`
-	Af                    float64 `json:"af,omitempty"`
-	GnomadV3Af            float64 `json:"gnomad_v3_af,omitempty"`
-	Hgvsg                 string  `json:"hgvsg,omitempty"`
-	OmimInheritanceCode   string  `json:"omim_inheritance_code,omitempty"`
-	AdRatio               float64 `json:"ad_ratio,omitempty"`
-	VariantClass          string  `json:"variant_class,omitempty"`
-	VepImpact             string  `json:"vep_impact,omitempty"`
-	Symbol                string  `json:"symbol,omitempty"`
-	ClinvarInterpretation string  `json:"clinvar_interpretation,omitempty"`
-	ManeSelect            bool    `json:"mane_select,omitempty"`
-	Canonical             bool    `json:"canonical,omitempty"`
-}
+import "github.com/Goldziher/go-utils/sliceutils"
 
 var OccurrenceTable = Table{
 	Name:  "occurrences",
 	Alias: "o",
 }
 
+var ZygosityValues = []string{"HOM", "HET", "WT", "UNK"}
+
 var FilterField = Field{
 	Name:          "filter",
 	CanBeSelected: true,
 	CanBeFiltered: true,
 	Table:         OccurrenceTable,
+	Type:          StringType,
 }
 var SeqIdField = Field{
 	Name:          "seq_id",
 	CanBeSelected: true,
 	CanBeFiltered: true,
 	Table:         OccurrenceTable,
+	Type:          IntType,
 }
 var LocusIdField = Field{
 	Name:          "locus_id",
 	CanBeSelected: true,
 	CanBeFiltered: true,
 	Table:         OccurrenceTable,
+	Type:          IntType,
 }
 var ZygosityField = Field{
 	Name:          "zygosity",
 	CanBeSelected: true,
 	CanBeFiltered: true,
 	Table:         OccurrenceTable,
+	Type:          EnumType,
+	EnumValues:    &ZygosityValues,
 }
 var AdRatioField = Field{
 	Name:          "ad_ratio",
 	CanBeSelected: true,
 	CanBeFiltered: true,
 	Table:         OccurrenceTable,
+	Type:          DecimalType,
 }
 var ChromosomeField = Field{
 	Name:          "chromosome",
@@ -62,17 +51,297 @@ var ChromosomeField = Field{
 	CanBeFiltered: true,
 	CanBeSorted:   true,
 	Table:         OccurrenceTable,
+	Type:          StringType,
+}
+var StartField = Field{
+	Name:          "start",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	CanBeSorted:   true,
+	Table:         OccurrenceTable,
+	Type:          IntType,
+}
+
+var AdTotalField = Field{
+	Name:          "ad_total",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	CanBeSorted:   true,
+	Table:         OccurrenceTable,
+	Type:          IntType,
+}
+var AdRefField = Field{
+	Name:          "ad_ref",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	CanBeSorted:   true,
+	Table:         OccurrenceTable,
+	Type:          IntType,
+}
+var AdAltField = Field{
+	Name:          "ad_alt",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	CanBeSorted:   true,
+	Table:         OccurrenceTable,
+	Type:          IntType,
+}
+var DpField = Field{
+	Name:          "dp",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	CanBeSorted:   true,
+	Table:         OccurrenceTable,
+	Type:          IntType,
+}
+var GqField = Field{
+	Name:          "gq",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	CanBeSorted:   true,
+	Table:         OccurrenceTable,
+	Type:          IntType,
+}
+var HasAltField = Field{
+	Name:          "has_alt",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         OccurrenceTable,
+	Type:          BoolType,
+}
+var QualityField = Field{
+	Name:          "quality",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	CanBeSorted:   true,
+	Table:         OccurrenceTable,
+	Type:          DecimalType,
+}
+var InfoAcField = Field{
+	Name:          "info_ac",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         OccurrenceTable,
+	Type:          IntType,
+}
+var InfoAnField = Field{
+	Name:          "info_an",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         OccurrenceTable,
+	Type:          IntType,
+}
+var InfoAfField = Field{
+	Name:          "info_af",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         OccurrenceTable,
+	Type:          DecimalType,
+}
+var InfoBaseqRankSumField = Field{
+	Name:          "info_baseq_rank_sum",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         OccurrenceTable,
+	Type:          DecimalType,
+}
+var InfoExcessHetField = Field{
+	Name:          "info_excess_het",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         OccurrenceTable,
+	Type:          DecimalType,
+}
+var InfoFsField = Field{
+	Name:          "info_fs",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         OccurrenceTable,
+	Type:          DecimalType,
+}
+var InfoDsField = Field{
+	Name:          "info_ds",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         OccurrenceTable,
+	Type:          BoolType,
+}
+var InfoFractionInformativeReadsField = Field{
+	Name:          "info_fraction_informative_reads",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         OccurrenceTable,
+	Type:          DecimalType,
+}
+var InfoInbreedCoeffField = Field{
+	Name:          "info_inbreed_coeff",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         OccurrenceTable,
+	Type:          DecimalType,
+}
+var InfoMleacField = Field{
+	Name:          "info_mleac",
+	CanBeSelected: true,
+	Table:         OccurrenceTable,
+	Type:          IntType,
+	IsArray:       true,
+}
+var InfoMleafField = Field{
+	Name:          "info_mleaf",
+	CanBeSelected: true,
+	Table:         OccurrenceTable,
+	Type:          DecimalType,
+	IsArray:       true,
+}
+var InfoMqField = Field{
+	Name:          "info_mq",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         OccurrenceTable,
+	Type:          DecimalType,
+}
+var InfoMQRankSumField = Field{
+	Name:          "info_m_qrank_sum",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         OccurrenceTable,
+	Type:          DecimalType,
+}
+var InfoQdField = Field{
+	Name:          "info_qd",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         OccurrenceTable,
+	Type:          DecimalType,
+}
+var InfoR25pBiasField = Field{
+	Name:          "info_r2_5p_bias",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         OccurrenceTable,
+	Type:          DecimalType,
+}
+var InfoReadPosRankSumField = Field{
+	Name:          "info_read_pos_rank_sum",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         OccurrenceTable,
+	Type:          DecimalType,
+}
+var InfoSorField = Field{
+	Name:          "info_sor",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         OccurrenceTable,
+	Type:          DecimalType,
+}
+var InfoVqslodField = Field{
+	Name:          "info_vqslod",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         OccurrenceTable,
+	Type:          DecimalType,
+}
+var InfoCulpritField = Field{
+	Name:          "info_culprit",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         OccurrenceTable,
+	Type:          StringType,
+}
+var InfoDpField = Field{
+	Name:          "info_dp",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         OccurrenceTable,
+	Type:          IntType,
+}
+var InfoHaplotypeScoreField = Field{
+	Name:          "info_haplotype_score",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         OccurrenceTable,
+	Type:          DecimalType,
+}
+var CallsField = Field{
+	Name:          "calls",
+	CanBeSelected: true,
+	Table:         OccurrenceTable,
+	Type:          IntType,
+	IsArray:       true,
 }
 
 var OccurrencesFields = []Field{
 	SeqIdField,
 	LocusIdField,
+	ChromosomeField,
+	StartField,
+	QualityField,
 	FilterField,
 	ZygosityField,
 	AdRatioField,
+	AdTotalField,
+	AdRefField,
+	AdAltField,
+	DpField,
+	GqField,
+	HasAltField,
+	InfoAcField,
+	InfoAnField,
+	InfoAfField,
+	InfoBaseqRankSumField,
+	InfoExcessHetField,
+	InfoFsField,
+	InfoDsField,
+	InfoFractionInformativeReadsField,
+	InfoInbreedCoeffField,
+	InfoMleacField,
+	InfoMleafField,
+	InfoMqField,
+	InfoMQRankSumField,
+	InfoQdField,
+	InfoR25pBiasField,
+	InfoReadPosRankSumField,
+	InfoSorField,
+	InfoVqslodField,
+	InfoCulpritField,
+	InfoDpField,
+	InfoHaplotypeScoreField,
+	CallsField,
 	PfField,
 	AfField,
-	VariantClassField,
+	AcField,
+	PcField,
+	HomField,
+	GnomadV3AfField,
 	HgvsgField,
-	ChromosomeField,
+	LocusFullField,
+	DnaChangeField,
+	ReferenceField,
+	AlternateField,
+	OmimInheritanceCodeField,
+	VariantClassField,
+	VepImpactField,
+	SymbolField,
+	ConsequenceField,
+	ClinvarInterpretationField,
+	RsnumberField,
+	ManeSelectField,
+	CanonicalField,
 }
+
+// CarrierSeqIdField is the sortable seq_id of the carriers of a variant, which are sorted by sequencing experiment
+var CarrierSeqIdField = Field{
+	Name:          "seq_id",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	CanBeSorted:   true,
+	Table:         OccurrenceTable,
+	Type:          IntType,
+}
+
+// CarrierFields are the occurrence fields, without the variant ones, usable when listing the carriers of a variant
+var CarrierFields = append([]Field{CarrierSeqIdField}, sliceutils.Filter(OccurrencesFields, func(field Field, index int, slice []Field) bool {
+	return field.Table == OccurrenceTable && field.Name != CarrierSeqIdField.Name
+})...)
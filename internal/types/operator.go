@@ -0,0 +1,146 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SQLOperator builds the SQL predicate applying an operation on a column with the given parameters.
+//
+// NULL values never match "in", "all", "<", ">", "<=", ">=" and "between", but always match "not-in":
+// excluding some values must keep the rows for which the value is unknown.
+type SQLOperator func(column string, params []interface{}) (string, []interface{})
+
+const (
+	DefaultOperators = "default"        // Operators for scalar columns
+	ArrayOperators   = "array_contains" // Operators for array columns
+)
+
+// operatorSets maps the name of a set of operators, as referenced by Field.CustomOp or Field.DefaultOp, to its SQL strategies
+var operatorSets = map[string]map[string]SQLOperator{
+	DefaultOperators: {
+		"in":       inOperator,
+		"not-in":   notInOperator,
+		"<":        compareOperator("<"),
+		">":        compareOperator(">"),
+		"<=":       compareOperator("<="),
+		">=":       compareOperator(">="),
+		"between":  betweenOperator,
+		"is-null":  isNullOperator,
+		"not-null": notNullOperator,
+		"missing":  isNullOperator,
+	},
+	ArrayOperators: {
+		"in":       arrayOverlapOperator,
+		"not-in":   arrayNotOverlapOperator,
+		"all":      arrayContainsAllOperator,
+		"is-null":  isNullOperator,
+		"not-null": notNullOperator,
+		"missing":  arrayMissingOperator,
+	},
+}
+
+// textOperators are the text search operations, available on the fields that can be searched
+var textOperators = map[string]SQLOperator{
+	"prefix":    likeOperator(false, "", "%"),
+	"contains":  likeOperator(false, "%", "%"),
+	"iprefix":   likeOperator(true, "", "%"),
+	"icontains": likeOperator(true, "%", "%"),
+}
+
+// findOperator returns the SQL strategy of an operation for a field, or nil if the field does not support it.
+// The operators of Field.CustomOp take precedence, then those of Field.DefaultOp, then the default ones.
+func findOperator(field *Field, op string) SQLOperator {
+	if operator, ok := textOperators[op]; ok {
+		if field.CanBeSearched && !field.IsArray {
+			return operator
+		}
+		return nil
+	}
+	setName := DefaultOperators
+	if field.CustomOp != "" {
+		setName = field.CustomOp
+	} else if field.DefaultOp != "" {
+		setName = field.DefaultOp
+	}
+	set, ok := operatorSets[setName]
+	if !ok {
+		set = operatorSets[DefaultOperators]
+	}
+	return set[op]
+}
+
+func inOperator(column string, params []interface{}) (string, []interface{}) {
+	if len(params) == 1 {
+		return fmt.Sprintf("%s = ?", column), params
+	}
+	return fmt.Sprintf("%s IN (%s)", column, placeholders(len(params))), params
+}
+
+func notInOperator(column string, params []interface{}) (string, []interface{}) {
+	if len(params) == 1 {
+		return fmt.Sprintf("(%s <> ? OR %s IS NULL)", column, column), params
+	}
+	return fmt.Sprintf("(%s NOT IN (%s) OR %s IS NULL)", column, placeholders(len(params)), column), params
+}
+
+func compareOperator(op string) SQLOperator {
+	return func(column string, params []interface{}) (string, []interface{}) {
+		return fmt.Sprintf("%s %s ?", column, op), params
+	}
+}
+
+func betweenOperator(column string, params []interface{}) (string, []interface{}) {
+	return fmt.Sprintf("%s BETWEEN ? AND ?", column), params
+}
+
+func arrayOverlapOperator(column string, params []interface{}) (string, []interface{}) {
+	return fmt.Sprintf("arrays_overlap(%s, [%s])", column, placeholders(len(params))), params
+}
+
+func arrayNotOverlapOperator(column string, params []interface{}) (string, []interface{}) {
+	return fmt.Sprintf("(NOT arrays_overlap(%s, [%s]) OR %s IS NULL)", column, placeholders(len(params)), column), params
+}
+
+func arrayContainsAllOperator(column string, params []interface{}) (string, []interface{}) {
+	return fmt.Sprintf("array_contains_all(%s, [%s])", column, placeholders(len(params))), params
+}
+
+func isNullOperator(column string, _ []interface{}) (string, []interface{}) {
+	return fmt.Sprintf("%s IS NULL", column), nil
+}
+
+func notNullOperator(column string, _ []interface{}) (string, []interface{}) {
+	return fmt.Sprintf("%s IS NOT NULL", column), nil
+}
+
+// arrayMissingOperator matches arrays that are NULL or empty
+func arrayMissingOperator(column string, _ []interface{}) (string, []interface{}) {
+	return fmt.Sprintf("(%s IS NULL OR array_length(%s) = 0)", column, column), nil
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// likeOperator matches any of the values, surrounded by the given wildcards. Wildcards in values are escaped so
+// that they match literally. Case-insensitive matching compares the lower-cased column and values.
+func likeOperator(ignoreCase bool, before string, after string) SQLOperator {
+	return func(column string, params []interface{}) (string, []interface{}) {
+		if ignoreCase {
+			column = fmt.Sprintf("lower(%s)", column)
+		}
+		parts := make([]string, len(params))
+		patterns := make([]interface{}, len(params))
+		for i, param := range params {
+			value := fmt.Sprint(param)
+			if ignoreCase {
+				value = strings.ToLower(value)
+			}
+			parts[i] = fmt.Sprintf("%s LIKE ?", column)
+			patterns[i] = before + likeEscaper.Replace(value) + after
+		}
+		if len(parts) == 1 {
+			return parts[0], patterns
+		}
+		return fmt.Sprintf("(%s)", strings.Join(parts, " OR ")), patterns
+	}
+}
@@ -0,0 +1,84 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var geneMetadata = Field{Name: "gene", CanBeFiltered: true, CanBeSearched: true, Type: StringType, Table: Table{Alias: "v"}}
+
+var searchFieldMetadata = []Field{
+	geneMetadata,
+	nameMetadata,
+}
+
+func TestQueryToSQLPrefix(t *testing.T) {
+	t.Parallel()
+	node := ComparisonNode{Operator: "prefix", Value: "BRCA", Field: geneMetadata}
+
+	sqlQuery, params := node.ToSQL()
+
+	assert.Equal(t, `v.gene LIKE ?`, sqlQuery)
+	assert.Equal(t, []interface{}{"BRCA%"}, params)
+}
+
+func TestQueryToSQLContainsMultipleValues(t *testing.T) {
+	t.Parallel()
+	node := ComparisonNode{Operator: "contains", Value: []interface{}{"c.68", "del"}, Field: geneMetadata}
+
+	sqlQuery, params := node.ToSQL()
+
+	assert.Equal(t, `(v.gene LIKE ? OR v.gene LIKE ?)`, sqlQuery)
+	assert.Equal(t, []interface{}{"%c.68%", "%del%"}, params)
+}
+
+func TestQueryToSQLIgnoreCase(t *testing.T) {
+	t.Parallel()
+	node := ComparisonNode{Operator: "icontains", Value: "BrCa", Field: geneMetadata}
+
+	sqlQuery, params := node.ToSQL()
+
+	assert.Equal(t, `lower(v.gene) LIKE ?`, sqlQuery)
+	assert.Equal(t, []interface{}{"%brca%"}, params)
+}
+
+func TestQueryToSQLPrefixEscapesWildcards(t *testing.T) {
+	t.Parallel()
+	node := ComparisonNode{Operator: "iprefix", Value: `50%_A\B`, Field: geneMetadata}
+
+	_, params := node.ToSQL()
+
+	assert.Equal(t, []interface{}{`50\%\_a\\b%`}, params)
+}
+
+func TestParseSQONTextSearch(t *testing.T) {
+	t.Parallel()
+	sqon := SQON{Op: "prefix", Field: "gene", Value: []interface{}{"BRCA", "TTN"}}
+
+	ast, visitedFields, err := parseSQONToAST(&sqon, &searchFieldMetadata)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []Field{geneMetadata}, visitedFields)
+		sqlQuery, params := ast.ToSQL()
+		assert.Equal(t, `(v.gene LIKE ? OR v.gene LIKE ?)`, sqlQuery)
+		assert.Equal(t, []interface{}{"BRCA%", "TTN%"}, params)
+	}
+}
+
+func TestParseSQONTextSearchNotSearchable(t *testing.T) {
+	t.Parallel()
+	sqon := SQON{Op: "contains", Field: "name", Value: "john"}
+
+	_, _, err := parseSQONToAST(&sqon, &searchFieldMetadata)
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "operation contains is not supported for field: name")
+}
+
+func TestParseSQONTextSearchEmptyValue(t *testing.T) {
+	t.Parallel()
+	sqon := SQON{Op: "prefix", Field: "gene", Value: ""}
+
+	_, _, err := parseSQONToAST(&sqon, &searchFieldMetadata)
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "invalid value  for field gene with operation prefix: expected non-empty string")
+}
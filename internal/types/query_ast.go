@@ -67,50 +67,22 @@ func (n *NotNode) ToSQL() (string, []interface{}) {
 }
 
 func (n *ComparisonNode) ToSQL() (string, []interface{}) {
-	var (
-		field  string
-		params []interface{}
-	)
-
-	if n.Field.Table.Alias != "" {
-		field = fmt.Sprintf("%s.%s", n.Field.Table.Alias, n.Field.Name)
-	} else {
-		field = n.Field.Name
-	}
+	var params []interface{}
+	field := n.Field.QualifiedName()
 
 	if v, ok := n.Value.([]interface{}); ok {
 		params = append(params, v...) // Flatten and append all elements
-	} else {
+	} else if n.Value != nil {
 		params = append(params, n.Value) // Append directly if not a slice
 	}
-	valueLength := len(params)
-
-	switch n.Operator {
-	case "in":
-		placeholder := placeholders(valueLength)
-		operator := "IN"
-		if valueLength == 1 {
-			return fmt.Sprintf("%s = %s", field, placeholder), params
-		}
-		return fmt.Sprintf("%s %s (%s)", field, operator, placeholder), params
-	case "not-in":
-		placeholder := placeholders(valueLength)
-		operator := "NOT IN"
-		if valueLength == 1 {
-			return fmt.Sprintf("%s <> %s", field, placeholder), params
-		}
-		return fmt.Sprintf("%s %s (%s)", field, operator, placeholder), params
-	case "<=", ">=", "<", ">":
-		return fmt.Sprintf("%s %s ?", field, n.Operator), params
-	case "between":
-		return fmt.Sprintf("%s BETWEEN ? AND ?", field), params
-	case "all":
-		return "", nil //TODO: implement
-	default:
+
+	operator := findOperator(&n.Field, n.Operator)
+	if operator == nil {
 		return "", nil //should not happen
 	}
-
+	return operator(field, params)
 }
+
 func placeholders(count int) string {
 	return strings.TrimSuffix(strings.Repeat("?, ", count), ", ")
 }
@@ -129,8 +101,9 @@ type SortField struct {
 }
 
 type Pagination struct {
-	Limit  int //Limit the number of results
-	Offset int //Offset the results
+	Limit  int     //Limit the number of results
+	Offset int     //Offset the results
+	Cursor *Cursor //Keyset pagination position, Offset is ignored when set
 }
 
 func BuildQuery(selected []string, sqon *SQON, fields *[]Field, pagination *Pagination, sorted []SortBody) (Query, error) {
@@ -141,6 +114,16 @@ func BuildQuery(selected []string, sqon *SQON, fields *[]Field, pagination *Pagi
 	// Define allowed sortedCols
 	sortedField := FindSortedFields(fields, sorted)
 
+	if pagination != nil && pagination.Cursor != nil {
+		var err error
+		if sortedField, err = addCursorTiebreaker(sortedField, fields); err != nil {
+			return Query{}, err
+		}
+		if pagination.Cursor.Values, err = DecodeCursor(pagination.Cursor.Token, sortedField); err != nil {
+			return Query{}, err
+		}
+	}
+
 	if sqon != nil {
 		root, visitedFilteredFields, err := parseSQONToAST(sqon, fields)
 		return Query{Filters: root, FilteredFields: visitedFilteredFields, SelectedFields: selectedFields, Pagination: pagination, SortedFields: sortedField}, err
@@ -198,14 +181,16 @@ func parseSQONToAST(sqon *SQON, fields *[]Field) (FilterNode, []Field, error) {
 		}
 		return &NotNode{Child: ast}, meta, nil
 
-	case "in", "not-in", "<", ">", "<=", ">=", "between", "all":
+	case "in", "not-in", "<", ">", "<=", ">=", "between", "all", "prefix", "contains", "iprefix", "icontains":
 		if sqon.Value == nil {
 			return nil, nil, fmt.Errorf("value must be defined: %s", sqon.Field)
 		}
-		meta := FindByName(fields, sqon.Field)
-
-		if meta == nil || !meta.CanBeFiltered {
-			return nil, nil, fmt.Errorf("unauthorized or unknown field: %s", sqon.Field)
+		if sqon.Field == GenePanelFieldName {
+			return parseGenePanelSQON(sqon, fields)
+		}
+		meta, err := findFilterableField(sqon, fields)
+		if err != nil {
+			return nil, nil, err
 		}
 
 		if sqon.Op == "between" {
@@ -218,14 +203,48 @@ func parseSQONToAST(sqon *SQON, fields *[]Field) (FilterNode, []Field, error) {
 			}
 		}
 
+		if sqon.Op == "in" || sqon.Op == "not-in" || sqon.Op == "all" {
+			if values, ok := sqon.Value.([]interface{}); ok && len(values) == 0 {
+				return nil, nil, fmt.Errorf("value array should contain at least one element when operation is '%s': %s", sqon.Op, sqon.Field)
+			}
+		}
+
+		_, isTextSearch := textOperators[sqon.Op]
+		if isTextSearch {
+			if err := validateSearchValue(sqon); err != nil {
+				return nil, nil, err
+			}
+		}
+
 		_, isMultipleValue := sqon.Value.([]interface{})
-		if sqon.Op != "in" && sqon.Op != "not-in" && sqon.Op != "all" && isMultipleValue {
+		if sqon.Op != "in" && sqon.Op != "not-in" && sqon.Op != "all" && sqon.Op != "between" && !isTextSearch && isMultipleValue {
 			return nil, nil, fmt.Errorf("operation %s must have exactly one value: %s", sqon.Op, sqon.Field)
 		}
 
+		value, err := CoerceValue(meta, sqon.Op, sqon.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return &ComparisonNode{
+			Operator: sqon.Op,
+			Value:    value,
+			Field:    *meta,
+		}, []Field{*meta}, nil
+
+	case "region":
+		return parseRegionSQON(sqon, fields)
+
+	case "is-null", "not-null", "missing":
+		if sqon.Value != nil {
+			return nil, nil, fmt.Errorf("operation %s must not have a value: %s", sqon.Op, sqon.Field)
+		}
+		meta, err := findFilterableField(sqon, fields)
+		if err != nil {
+			return nil, nil, err
+		}
 		return &ComparisonNode{
 			Operator: sqon.Op,
-			Value:    sqon.Value,
 			Field:    *meta,
 		}, []Field{*meta}, nil
 
@@ -233,3 +252,34 @@ func parseSQONToAST(sqon *SQON, fields *[]Field) (FilterNode, []Field, error) {
 		return nil, nil, fmt.Errorf("invalid operation: %s", sqon.Op)
 	}
 }
+
+// validateSearchValue checks that the values of a text search SQON are non-empty strings
+func validateSearchValue(sqon *SQON) error {
+	values, ok := sqon.Value.([]interface{})
+	if !ok {
+		values = []interface{}{sqon.Value}
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("value array should contain at least one element when operation is '%s': %s", sqon.Op, sqon.Field)
+	}
+	for _, v := range values {
+		if s, ok := v.(string); !ok || s == "" {
+			return fmt.Errorf("invalid value %v for field %s with operation %s: expected non-empty string", v, sqon.Field, sqon.Op)
+		}
+	}
+	return nil
+}
+
+// findFilterableField returns the field targeted by a leaf SQON if it can be filtered with the SQON operation
+func findFilterableField(sqon *SQON, fields *[]Field) (*Field, error) {
+	meta := FindByName(fields, sqon.Field)
+
+	if meta == nil || !meta.CanBeFiltered {
+		return nil, fmt.Errorf("unauthorized or unknown field: %s", sqon.Field)
+	}
+
+	if findOperator(meta, sqon.Op) == nil {
+		return nil, fmt.Errorf("operation %s is not supported for field: %s", sqon.Op, sqon.Field)
+	}
+	return meta, nil
+}
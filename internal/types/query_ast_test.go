@@ -1,6 +1,7 @@
 package types
 
 import (
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -381,9 +382,233 @@ func TestQueryCompleteToSQL(t *testing.T) {
 
 	sqlQuery, params := node.ToSQL()
 
-	expectedSQL := `(age IN (?, ?) OR (age IN (?, ?) AND salary >= ?) OR hobbies IN (?, ?) OR NOT (city NOT IN (?, ?)))`
+	expectedSQL := `(age IN (?, ?) OR (age IN (?, ?) AND salary >= ?) OR arrays_overlap(hobbies, [?, ?]) OR NOT ((city NOT IN (?, ?) OR city IS NULL)))`
 	expectedParams := []interface{}{30, 40, 10, 20, 50000, "soccer", "hiking", "New York", "Los Angeles"}
 
 	assert.Equal(t, expectedSQL, sqlQuery)
 	assert.Equal(t, expectedParams, params)
 }
+
+func TestQueryToSQLAll(t *testing.T) {
+	t.Parallel()
+	node := ComparisonNode{Operator: "all", Value: []interface{}{"soccer", "hiking"}, Field: hobbiesMetadata}
+
+	sqlQuery, params := node.ToSQL()
+
+	expectedSQL := `array_contains_all(hobbies, [?, ?])`
+	expectedParams := []interface{}{"soccer", "hiking"}
+
+	assert.Equal(t, expectedSQL, sqlQuery)
+	assert.Equal(t, expectedParams, params)
+}
+
+func TestQueryToSQLAllSingleValue(t *testing.T) {
+	t.Parallel()
+	node := ComparisonNode{Operator: "all", Value: "soccer", Field: Field{Name: "hobbies", CanBeFiltered: true, CustomOp: "array_contains", Table: Table{Alias: "v"}}}
+
+	sqlQuery, params := node.ToSQL()
+
+	expectedSQL := `array_contains_all(v.hobbies, [?])`
+	expectedParams := []interface{}{"soccer"}
+
+	assert.Equal(t, expectedSQL, sqlQuery)
+	assert.Equal(t, expectedParams, params)
+}
+
+func TestQueryToSQLAllInTree(t *testing.T) {
+	t.Parallel()
+	sqon := SQON{
+		Op: "and",
+		Content: []SQON{
+			{Op: ">=", Field: "age", Value: 30},
+			{
+				Op: "or",
+				Content: []SQON{
+					{Op: "all", Field: "hobbies", Value: []interface{}{"soccer", "hiking"}},
+					{Op: "not", Content: []SQON{
+						{Op: "all", Field: "hobbies", Value: []interface{}{"chess"}},
+					}},
+				},
+			},
+		},
+	}
+
+	ast, _, err := parseSQONToAST(&sqon, &fieldMetadata)
+	if assert.NoError(t, err) {
+		sqlQuery, params := ast.ToSQL()
+		expectedSQL := `(age >= ? AND (array_contains_all(hobbies, [?, ?]) OR NOT (array_contains_all(hobbies, [?]))))`
+		expectedParams := []interface{}{30, "soccer", "hiking", "chess"}
+
+		assert.Equal(t, expectedSQL, sqlQuery)
+		assert.Equal(t, expectedParams, params)
+	}
+}
+
+func TestParseSQONAllWithEmptyArray(t *testing.T) {
+	t.Parallel()
+	sqon := SQON{
+		Op:    "all",
+		Field: "hobbies",
+		Value: []interface{}{},
+	}
+
+	_, _, err := parseSQONToAST(&sqon, &fieldMetadata)
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "value array should contain at least one element when operation is 'all': hobbies")
+}
+
+func TestParseSQONInWithEmptyArray(t *testing.T) {
+	t.Parallel()
+	for _, op := range []string{"in", "not-in"} {
+		for _, field := range []string{"age", "hobbies"} {
+			sqon := SQON{
+				Op:    op,
+				Field: field,
+				Value: []interface{}{},
+			}
+
+			_, _, err := parseSQONToAST(&sqon, &fieldMetadata)
+			assert.EqualError(t, err, fmt.Sprintf("value array should contain at least one element when operation is '%s': %s", op, field))
+		}
+	}
+}
+
+func TestQueryToSQLInArray(t *testing.T) {
+	t.Parallel()
+	node := ComparisonNode{Operator: "in", Value: []interface{}{"soccer", "hiking"}, Field: hobbiesMetadata}
+
+	sqlQuery, params := node.ToSQL()
+
+	expectedSQL := `arrays_overlap(hobbies, [?, ?])`
+	expectedParams := []interface{}{"soccer", "hiking"}
+
+	assert.Equal(t, expectedSQL, sqlQuery)
+	assert.Equal(t, expectedParams, params)
+}
+
+func TestQueryToSQLNotInArray(t *testing.T) {
+	t.Parallel()
+	node := ComparisonNode{Operator: "not-in", Value: "soccer", Field: hobbiesMetadata}
+
+	sqlQuery, params := node.ToSQL()
+
+	expectedSQL := `(NOT arrays_overlap(hobbies, [?]) OR hobbies IS NULL)`
+	expectedParams := []interface{}{"soccer"}
+
+	assert.Equal(t, expectedSQL, sqlQuery)
+	assert.Equal(t, expectedParams, params)
+}
+
+func TestParseSQONUnsupportedOperationForArray(t *testing.T) {
+	t.Parallel()
+	sqon := SQON{
+		Op:    ">",
+		Field: "hobbies",
+		Value: "soccer",
+	}
+
+	_, _, err := parseSQONToAST(&sqon, &fieldMetadata)
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "operation > is not supported for field: hobbies")
+}
+
+func TestParseSQONUnsupportedAllForScalar(t *testing.T) {
+	t.Parallel()
+	sqon := SQON{
+		Op:    "all",
+		Field: "city",
+		Value: []interface{}{"New York"},
+	}
+
+	_, _, err := parseSQONToAST(&sqon, &fieldMetadata)
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "operation all is not supported for field: city")
+}
+
+func TestQueryToSQLNotIn(t *testing.T) {
+	t.Parallel()
+	node := ComparisonNode{Operator: "not-in", Value: []interface{}{"New York", "Los Angeles"}, Field: cityMetadata}
+
+	sqlQuery, params := node.ToSQL()
+
+	expectedSQL := `(city NOT IN (?, ?) OR city IS NULL)`
+	expectedParams := []interface{}{"New York", "Los Angeles"}
+
+	assert.Equal(t, expectedSQL, sqlQuery)
+	assert.Equal(t, expectedParams, params)
+}
+
+func TestQueryToSQLNotInSingleValue(t *testing.T) {
+	t.Parallel()
+	node := ComparisonNode{Operator: "not-in", Value: "New York", Field: cityMetadata}
+
+	sqlQuery, params := node.ToSQL()
+
+	expectedSQL := `(city <> ? OR city IS NULL)`
+	expectedParams := []interface{}{"New York"}
+
+	assert.Equal(t, expectedSQL, sqlQuery)
+	assert.Equal(t, expectedParams, params)
+}
+
+func TestQueryToSQLIsNull(t *testing.T) {
+	t.Parallel()
+	node := ComparisonNode{Operator: "is-null", Field: cityMetadata}
+
+	sqlQuery, params := node.ToSQL()
+
+	assert.Equal(t, `city IS NULL`, sqlQuery)
+	assert.Empty(t, params)
+}
+
+func TestQueryToSQLNotNull(t *testing.T) {
+	t.Parallel()
+	node := ComparisonNode{Operator: "not-null", Field: hobbiesMetadata}
+
+	sqlQuery, params := node.ToSQL()
+
+	assert.Equal(t, `hobbies IS NOT NULL`, sqlQuery)
+	assert.Empty(t, params)
+}
+
+func TestQueryToSQLMissingArray(t *testing.T) {
+	t.Parallel()
+	node := ComparisonNode{Operator: "missing", Field: hobbiesMetadata}
+
+	sqlQuery, params := node.ToSQL()
+
+	assert.Equal(t, `(hobbies IS NULL OR array_length(hobbies) = 0)`, sqlQuery)
+	assert.Empty(t, params)
+}
+
+func TestParseSQONNullOperators(t *testing.T) {
+	t.Parallel()
+	sqon := SQON{
+		Op: "or",
+		Content: []SQON{
+			{Op: "missing", Field: "city"},
+			{Op: "not-null", Field: "hobbies"},
+		},
+	}
+
+	ast, visitedFields, err := parseSQONToAST(&sqon, &fieldMetadata)
+	if assert.NoError(t, err) {
+		assert.ElementsMatch(t, []Field{cityMetadata, hobbiesMetadata}, visitedFields)
+		sqlQuery, params := ast.ToSQL()
+		assert.Equal(t, `(city IS NULL OR hobbies IS NOT NULL)`, sqlQuery)
+		assert.Empty(t, params)
+	}
+}
+
+func TestParseSQONNullOperatorWithValue(t *testing.T) {
+	t.Parallel()
+	sqon := SQON{
+		Op:    "is-null",
+		Field: "city",
+		Value: true,
+	}
+
+	_, _, err := parseSQONToAST(&sqon, &fieldMetadata)
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "operation is-null must not have a value: city")
+}
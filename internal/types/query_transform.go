@@ -0,0 +1,66 @@
+package types
+
+import "github.com/Goldziher/go-utils/sliceutils"
+
+// WithoutFiltersOn returns a copy of the query without the top-level "and" clauses filtering only on the field.
+// A root that is not an "and" is considered as a single clause.
+func (q *Query) WithoutFiltersOn(field Field) Query {
+	result := *q
+	result.Filters = removeClausesOn(q.Filters, field)
+	result.FilteredFields = filteredFields(result.Filters)
+	return result
+}
+
+func removeClausesOn(root FilterNode, field Field) FilterNode {
+	if root == nil {
+		return nil
+	}
+	and, ok := root.(*AndNode)
+	if !ok {
+		if filtersOnlyOn(root, field) {
+			return nil
+		}
+		return root
+	}
+	children := sliceutils.Filter(and.Children, func(child FilterNode, index int, slice []FilterNode) bool {
+		return !filtersOnlyOn(child, field)
+	})
+	switch len(children) {
+	case 0:
+		return nil
+	case 1:
+		return children[0]
+	default:
+		return &AndNode{Children: children}
+	}
+}
+
+// filtersOnlyOn returns whether all the filters of the node are on the field
+func filtersOnlyOn(node FilterNode, field Field) bool {
+	fields := filteredFields(node)
+	return len(fields) > 0 && sliceutils.Every(fields, func(f Field, index int, slice []Field) bool {
+		return f.Name == field.Name && f.Table == field.Table
+	})
+}
+
+// filteredFields returns the fields used in the filters of the node
+func filteredFields(node FilterNode) []Field {
+	var fields []Field
+	switch n := node.(type) {
+	case *AndNode:
+		for _, child := range n.Children {
+			fields = append(fields, filteredFields(child)...)
+		}
+	case *OrNode:
+		for _, child := range n.Children {
+			fields = append(fields, filteredFields(child)...)
+		}
+	case *NotNode:
+		fields = filteredFields(n.Child)
+	case *ComparisonNode:
+		fields = []Field{n.Field}
+	case *RegionNode:
+		fields = []Field{n.ChromosomeField, n.StartField}
+	}
+	return sliceutils.Unique(fields)
+}
@@ -0,0 +1,97 @@
+package types
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWithoutFiltersOn(t *testing.T) {
+	t.Parallel()
+
+	sqon := SQON{
+		Op: "and",
+		Content: []SQON{
+			{Op: "in", Field: "age", Value: []interface{}{30, 40}},
+			{Op: "or", Content: []SQON{
+				{Op: ">", Field: "age", Value: 50},
+				{Op: "is-null", Field: "age"},
+			}},
+			{Op: ">", Field: "salary", Value: 50000},
+			{Op: "or", Content: []SQON{
+				{Op: "<", Field: "age", Value: 20},
+				{Op: "in", Field: "city", Value: "Montreal"},
+			}},
+		},
+	}
+	query, err := BuildQuery(nil, &sqon, &fieldMetadata, nil, nil)
+	if assert.NoError(t, err) {
+		result := query.WithoutFiltersOn(ageMetadata)
+		sql, params := result.Filters.ToSQL()
+		assert.Equal(t, "(salary > ? AND (age < ? OR city = ?))", sql)
+		assert.Equal(t, []interface{}{50000, 20, "Montreal"}, params)
+		assert.ElementsMatch(t, []Field{salaryMetadata, ageMetadata, cityMetadata}, result.FilteredFields)
+		assert.Len(t, query.Filters.(*AndNode).Children, 4, "original query should not be modified")
+	}
+}
+
+func TestWithoutFiltersOnSingleRemainingClause(t *testing.T) {
+	t.Parallel()
+
+	sqon := SQON{
+		Op: "and",
+		Content: []SQON{
+			{Op: "in", Field: "age", Value: []interface{}{30, 40}},
+			{Op: "not", Content: []SQON{{Op: "in", Field: "city", Value: "Montreal"}}},
+		},
+	}
+	query, err := BuildQuery(nil, &sqon, &fieldMetadata, nil, nil)
+	if assert.NoError(t, err) {
+		result := query.WithoutFiltersOn(ageMetadata)
+		sql, params := result.Filters.ToSQL()
+		assert.Equal(t, "NOT (city = ?)", sql)
+		assert.Equal(t, []interface{}{"Montreal"}, params)
+		assert.Equal(t, []Field{cityMetadata}, result.FilteredFields)
+	}
+}
+
+func TestWithoutFiltersOnRoot(t *testing.T) {
+	t.Parallel()
+
+	sqon := SQON{Op: "in", Field: "age", Value: []interface{}{30, 40}}
+	query, err := BuildQuery(nil, &sqon, &fieldMetadata, nil, nil)
+	if assert.NoError(t, err) {
+		result := query.WithoutFiltersOn(ageMetadata)
+		assert.Nil(t, result.Filters)
+		assert.Empty(t, result.FilteredFields)
+
+		result = query.WithoutFiltersOn(cityMetadata)
+		assert.Equal(t, query.Filters, result.Filters)
+		assert.Equal(t, []Field{ageMetadata}, result.FilteredFields)
+	}
+}
+
+func TestWithoutFiltersOnOrRoot(t *testing.T) {
+	t.Parallel()
+
+	sqon := SQON{
+		Op: "or",
+		Content: []SQON{
+			{Op: "in", Field: "age", Value: []interface{}{30, 40}},
+			{Op: "in", Field: "city", Value: "Montreal"},
+		},
+	}
+	query, err := BuildQuery(nil, &sqon, &fieldMetadata, nil, nil)
+	if assert.NoError(t, err) {
+		result := query.WithoutFiltersOn(ageMetadata)
+		assert.Equal(t, query.Filters, result.Filters)
+	}
+}
+
+func TestWithoutFiltersOnNoFilter(t *testing.T) {
+	t.Parallel()
+
+	query := Query{SelectedFields: []Field{ageMetadata}}
+	result := query.WithoutFiltersOn(ageMetadata)
+	assert.Nil(t, result.Filters)
+	assert.Equal(t, []Field{ageMetadata}, result.SelectedFields)
+}
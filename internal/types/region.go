@@ -0,0 +1,156 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Goldziher/go-utils/sliceutils"
+)
+
+// Region is a genomic interval, with 1-based inclusive positions
+type Region struct {
+	Chromosome string // Chromosome name without "chr" prefix, e.g. "17" or "X"
+	Start      int64  // First position of the interval, 0 if the region covers the whole chromosome
+	End        int64  // Last position of the interval, 0 if the region covers the whole chromosome
+}
+
+// RegionNode matches the rows located in any of its regions
+type RegionNode struct {
+	Regions         []Region
+	ChromosomeField Field // Field holding the chromosome of the row
+	StartField      Field // Field holding the position of the row
+}
+
+var chromosomes = []string{
+	"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12",
+	"13", "14", "15", "16", "17", "18", "19", "20", "21", "22", "X", "Y", "M",
+}
+
+var regionPattern = regexp.MustCompile(`^([^:]+)(?::([\d,]+)-([\d,]+))?$`)
+
+func (n *RegionNode) ToSQL() (string, []interface{}) {
+	chromosome := n.ChromosomeField.QualifiedName()
+	start := n.StartField.QualifiedName()
+	parts := make([]string, len(n.Regions))
+	var params []interface{}
+	for i, region := range n.Regions {
+		if region.Start == 0 && region.End == 0 {
+			parts[i] = fmt.Sprintf("%s = ?", chromosome)
+			params = append(params, region.Chromosome)
+		} else {
+			parts[i] = fmt.Sprintf("(%s = ? AND %s BETWEEN ? AND ?)", chromosome, start)
+			params = append(params, region.Chromosome, region.Start, region.End)
+		}
+	}
+	return fmt.Sprintf("(%s)", strings.Join(parts, " OR ")), params
+}
+
+// NormalizeChromosome removes the "chr" prefix of a chromosome name and returns an error if the chromosome is unknown
+func NormalizeChromosome(name string) (string, error) {
+	chromosome := strings.ToUpper(strings.TrimSpace(name))
+	chromosome = strings.TrimPrefix(chromosome, "CHR")
+	if chromosome == "MT" {
+		chromosome = "M"
+	}
+	if !sliceutils.Includes(chromosomes, chromosome) {
+		return "", fmt.Errorf("unknown chromosome: %s", name)
+	}
+	return chromosome, nil
+}
+
+// ParseRegion parses a region given either as a string like "chr17:43044295-43125483" or "17", using 1-based
+// inclusive positions, or as a BED-like object {"chrom": "chr17", "start": 43044294, "end": 43125483} using
+// 0-based half-open positions.
+func ParseRegion(value interface{}) (Region, error) {
+	switch v := value.(type) {
+	case string:
+		return parseRegionString(v)
+	case map[string]interface{}:
+		return parseBedRegion(v)
+	default:
+		return Region{}, fmt.Errorf("invalid region %v: expected a string or an object", value)
+	}
+}
+
+func parseRegionString(value string) (Region, error) {
+	matches := regionPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if matches == nil {
+		return Region{}, fmt.Errorf("invalid region %s: expected chromosome:start-end", value)
+	}
+	chromosome, err := NormalizeChromosome(matches[1])
+	if err != nil {
+		return Region{}, fmt.Errorf("invalid region %s: %w", value, err)
+	}
+	if matches[2] == "" {
+		return Region{Chromosome: chromosome}, nil
+	}
+	start, err := strconv.ParseInt(strings.ReplaceAll(matches[2], ",", ""), 10, 64)
+	if err != nil {
+		return Region{}, fmt.Errorf("invalid region %s: invalid start %s", value, matches[2])
+	}
+	end, err := strconv.ParseInt(strings.ReplaceAll(matches[3], ",", ""), 10, 64)
+	if err != nil {
+		return Region{}, fmt.Errorf("invalid region %s: invalid end %s", value, matches[3])
+	}
+	return newRegion(value, chromosome, start, end)
+}
+
+func parseBedRegion(value map[string]interface{}) (Region, error) {
+	name, ok := value["chrom"].(string)
+	if !ok {
+		return Region{}, fmt.Errorf("invalid region %v: chrom must be a string", value)
+	}
+	chromosome, err := NormalizeChromosome(name)
+	if err != nil {
+		return Region{}, fmt.Errorf("invalid region %v: %w", value, err)
+	}
+	start, okStart := value["start"].(float64)
+	end, okEnd := value["end"].(float64)
+	if !okStart || !okEnd || start != float64(int64(start)) || end != float64(int64(end)) {
+		return Region{}, fmt.Errorf("invalid region %v: start and end must be integers", value)
+	}
+	// BED intervals are 0-based and half-open
+	return newRegion(value, chromosome, int64(start)+1, int64(end))
+}
+
+func newRegion(value interface{}, chromosome string, start int64, end int64) (Region, error) {
+	if start < 1 || end < start {
+		return Region{}, fmt.Errorf("invalid region %v: start must be positive and lower or equal to end", value)
+	}
+	return Region{Chromosome: chromosome, Start: start, End: end}, nil
+}
+
+// parseRegionSQON builds a RegionNode from a "region" SQON whose value is one or many regions
+func parseRegionSQON(sqon *SQON, fields *[]Field) (FilterNode, []Field, error) {
+	if sqon.Value == nil {
+		return nil, nil, fmt.Errorf("value must be defined: %s", sqon.Op)
+	}
+	chromosomeField := FindByName(fields, "chromosome")
+	startField := FindByName(fields, "start")
+	if chromosomeField == nil || !chromosomeField.CanBeFiltered || startField == nil || !startField.CanBeFiltered {
+		return nil, nil, fmt.Errorf("operation region is not supported")
+	}
+
+	values, ok := sqon.Value.([]interface{})
+	if !ok {
+		values = []interface{}{sqon.Value}
+	}
+	if len(values) == 0 {
+		return nil, nil, fmt.Errorf("value array should contain at least one element when operation is 'region'")
+	}
+	regions := make([]Region, len(values))
+	for i, v := range values {
+		region, err := ParseRegion(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		regions[i] = region
+	}
+	return &RegionNode{
+		Regions:         regions,
+		ChromosomeField: *chromosomeField,
+		StartField:      *startField,
+	}, []Field{*chromosomeField, *startField}, nil
+}
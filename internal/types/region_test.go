@@ -0,0 +1,133 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var chromosomeMetadata = Field{Name: "chromosome", CanBeFiltered: true, Type: StringType, Table: Table{Alias: "o"}}
+var startMetadata = Field{Name: "start", CanBeFiltered: true, Type: IntType, Table: Table{Alias: "o"}}
+
+var locusFieldMetadata = []Field{
+	chromosomeMetadata,
+	startMetadata,
+	ageMetadata,
+}
+
+func TestParseRegionWithPrefix(t *testing.T) {
+	t.Parallel()
+	region, err := ParseRegion("chr17:43044295-43125483")
+	assert.NoError(t, err)
+	assert.Equal(t, Region{Chromosome: "17", Start: 43044295, End: 43125483}, region)
+}
+
+func TestParseRegionWithoutPrefixAndCommas(t *testing.T) {
+	t.Parallel()
+	region, err := ParseRegion("x:1,000-2,000")
+	assert.NoError(t, err)
+	assert.Equal(t, Region{Chromosome: "X", Start: 1000, End: 2000}, region)
+}
+
+func TestParseRegionWholeChromosome(t *testing.T) {
+	t.Parallel()
+	region, err := ParseRegion("chrMT")
+	assert.NoError(t, err)
+	assert.Equal(t, Region{Chromosome: "M"}, region)
+}
+
+func TestParseRegionBed(t *testing.T) {
+	t.Parallel()
+	region, err := ParseRegion(map[string]interface{}{"chrom": "chr1", "start": float64(99), "end": float64(200)})
+	assert.NoError(t, err)
+	assert.Equal(t, Region{Chromosome: "1", Start: 100, End: 200}, region)
+}
+
+func TestParseRegionUnknownChromosome(t *testing.T) {
+	t.Parallel()
+	_, err := ParseRegion("chr25:1-10")
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "invalid region chr25:1-10: unknown chromosome: chr25")
+}
+
+func TestParseRegionInvalidInterval(t *testing.T) {
+	t.Parallel()
+	_, err := ParseRegion("chr1:200-100")
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "start must be positive and lower or equal to end")
+}
+
+func TestParseRegionOverflowingPosition(t *testing.T) {
+	t.Parallel()
+	_, err := ParseRegion("chr1:100-99999999999999999999")
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "invalid region chr1:100-99999999999999999999: invalid end 99999999999999999999")
+}
+
+func TestParseRegionInvalidFormat(t *testing.T) {
+	t.Parallel()
+	_, err := ParseRegion("chr1:100")
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "expected chromosome:start-end")
+}
+
+func TestRegionNodeToSQL(t *testing.T) {
+	t.Parallel()
+	node := RegionNode{
+		Regions: []Region{
+			{Chromosome: "17", Start: 43044295, End: 43125483},
+			{Chromosome: "X"},
+		},
+		ChromosomeField: chromosomeMetadata,
+		StartField:      startMetadata,
+	}
+
+	sqlQuery, params := node.ToSQL()
+
+	expectedSQL := `((o.chromosome = ? AND o.start BETWEEN ? AND ?) OR o.chromosome = ?)`
+	expectedParams := []interface{}{"17", int64(43044295), int64(43125483), "X"}
+
+	assert.Equal(t, expectedSQL, sqlQuery)
+	assert.Equal(t, expectedParams, params)
+}
+
+func TestParseSQONRegion(t *testing.T) {
+	t.Parallel()
+	sqon := SQON{
+		Op: "and",
+		Content: []SQON{
+			{Op: "region", Value: []interface{}{"chr1:100-200", map[string]interface{}{"chrom": "2", "start": float64(0), "end": float64(10)}}},
+			{Op: ">", Field: "age", Value: 30},
+		},
+	}
+
+	ast, visitedFields, err := parseSQONToAST(&sqon, &locusFieldMetadata)
+	if assert.NoError(t, err) {
+		assert.ElementsMatch(t, []Field{chromosomeMetadata, startMetadata, ageMetadata}, visitedFields)
+		sqlQuery, params := ast.ToSQL()
+		assert.Equal(t, `(((o.chromosome = ? AND o.start BETWEEN ? AND ?) OR (o.chromosome = ? AND o.start BETWEEN ? AND ?)) AND age > ?)`, sqlQuery)
+		assert.Equal(t, []interface{}{"1", int64(100), int64(200), "2", int64(1), int64(10), 30}, params)
+	}
+}
+
+func TestParseSQONRegionSingleValue(t *testing.T) {
+	t.Parallel()
+	sqon := SQON{Op: "region", Value: "chr17:43044295-43125483"}
+
+	ast, _, err := parseSQONToAST(&sqon, &locusFieldMetadata)
+	if assert.NoError(t, err) {
+		regionNode, ok := ast.(*RegionNode)
+		if assert.True(t, ok) {
+			assert.Equal(t, []Region{{Chromosome: "17", Start: 43044295, End: 43125483}}, regionNode.Regions)
+		}
+	}
+}
+
+func TestParseSQONRegionWithoutLocusFields(t *testing.T) {
+	t.Parallel()
+	sqon := SQON{Op: "region", Value: "chr17:43044295-43125483"}
+
+	_, _, err := parseSQONToAST(&sqon, &fieldMetadata)
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "operation region is not supported")
+}
@@ -13,6 +13,18 @@ type ListBody struct {
 	Limit          int        `json:"limit"`
 	Offset         int        `json:"offset"`
 	Sort           []SortBody `json:"sort"`
+	Cursor         *string    `json:"cursor"`   // Enables keyset pagination when set, empty for the first page
+	Envelope       bool       `json:"envelope"` // Wraps the rows with the total count and pagination metadata
+}
+
+type ListResponse struct {
+	Data       []Row      `json:"data"`
+	Total      int64      `json:"total"`
+	Limit      int        `json:"limit"`
+	Offset     int        `json:"offset"`
+	HasMore    bool       `json:"has_more"`
+	Sort       []SortBody `json:"sort"`
+	NextCursor *string    `json:"next_cursor,omitempty"`
 }
 
 type SortBody struct {
@@ -25,7 +37,59 @@ type CountBody struct {
 }
 
 type AggregationBody struct {
-	Field string
-	SQON  *SQON
-	Size  int
+	Field       string `json:"field"`
+	SQON        *SQON  `json:"sqon"`
+	Size        int    `json:"size"`
+	Order       string `json:"order"`
+	Missing     bool   `json:"missing"`
+	ExcludeSelf bool   `json:"exclude_self"` // Ignores the top-level filters on the aggregated field
+}
+
+type FacetsBody struct {
+	Fields      []string `json:"fields"`
+	SQON        *SQON    `json:"sqon"`
+	Size        int      `json:"size"`
+	Order       string   `json:"order"`
+	Missing     bool     `json:"missing"`
+	ExcludeSelf bool     `json:"exclude_self"` // Ignores the top-level filters on the field of each facet
+}
+
+type HistogramBody struct {
+	Field       string  `json:"field"`
+	SQON        *SQON   `json:"sqon"`
+	Interval    float64 `json:"interval"`
+	Ranges      []Range `json:"ranges"`
+	LogScale    bool    `json:"log_scale"`
+	ExcludeSelf bool    `json:"exclude_self"` // Ignores the top-level filters on the aggregated field
+}
+
+type StatsBody struct {
+	Fields      []string  `json:"fields"`
+	SQON        *SQON     `json:"sqon"`
+	Percentiles []float64 `json:"percentiles"`
+}
+
+type FamilyListBody struct {
+	ListBody
+	Inheritance InheritanceThresholds `json:"inheritance"`
+}
+
+type FamilyAggregationBody struct {
+	AggregationBody
+	Inheritance InheritanceThresholds `json:"inheritance"`
+}
+
+type CompoundHetBody struct {
+	SelectedFields []string `json:"selected_fields"`
+	SQON           *SQON    `json:"sqon"`
+	Limit          int      `json:"limit"` // Number of genes per page
+	Offset         int      `json:"offset"`
+}
+
+type CohortBody struct {
+	SeqIds   []int   `json:"seq_ids"`
+	CohortId *string `json:"cohort_id"` // Cohort whose sequencing experiments are queried, instead of seq_ids
+	SQON     *SQON   `json:"sqon"`
+	Limit    int     `json:"limit"` // Number of loci returned when listing carriers
+	Offset   int     `json:"offset"`
 }
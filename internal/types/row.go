@@ -0,0 +1,115 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Goldziher/go-utils/sliceutils"
+)
+
+// Row is a result row, with values keyed by the aliases of the selected fields and kept in selection order.
+// NULL values are kept as nil, so that they are distinguishable from zero values.
+type Row struct {
+	Columns []string
+	Values  []interface{}
+	Keyset  []interface{} // Values of the sorted fields when using keyset pagination, not serialized
+}
+
+// NewRow converts the raw values scanned from the database to the types of the fields
+func NewRow(fields []Field, raw []interface{}) (Row, error) {
+	row := Row{Columns: make([]string, len(fields)), Values: make([]interface{}, len(fields))}
+	for i, field := range fields {
+		value, err := convertValue(&field, raw[i])
+		if err != nil {
+			return row, fmt.Errorf("error converting value of %s: %w", field.GetAlias(), err)
+		}
+		row.Columns[i] = field.GetAlias()
+		row.Values[i] = value
+	}
+	return row, nil
+}
+
+// Get returns the value of a column, or nil if the column does not exist
+func (r Row) Get(column string) interface{} {
+	i := sliceutils.FindIndexOf(r.Columns, column)
+	if i < 0 {
+		return nil
+	}
+	return r.Values[i]
+}
+
+// MarshalJSON writes the row as a JSON object whose keys are in selection order
+func (r Row) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, column := range r.Columns {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(column)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(r.Values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// convertValue converts a raw value returned by the driver, usually []byte, to the type of the field.
+// Dates are returned as YYYY-MM-DD and other times in RFC 3339 format.
+func convertValue(field *Field, raw interface{}) (interface{}, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	var text string
+	switch v := raw.(type) {
+	case []byte:
+		text = string(v)
+	case string:
+		text = v
+	case int64:
+		text = strconv.FormatInt(v, 10)
+	case uint64:
+		text = strconv.FormatUint(v, 10)
+	case float64:
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		text = strconv.FormatBool(v)
+	case time.Time:
+		if field.Type == DateType {
+			return v.Format(time.DateOnly), nil
+		}
+		return v.Format(time.RFC3339), nil
+	default:
+		return nil, fmt.Errorf("unsupported value %v of type %T", raw, raw)
+	}
+	if field.IsArray {
+		var values []interface{}
+		decoder := json.NewDecoder(bytes.NewBufferString(text))
+		decoder.UseNumber()
+		if err := decoder.Decode(&values); err != nil {
+			return nil, fmt.Errorf("invalid array %s: %w", text, err)
+		}
+		return values, nil
+	}
+	switch field.Type {
+	case IntType:
+		return strconv.ParseInt(text, 10, 64)
+	case DecimalType:
+		return strconv.ParseFloat(text, 64)
+	case BoolType:
+		return strconv.ParseBool(text)
+	default:
+		return text, nil
+	}
+}
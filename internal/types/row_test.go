@@ -0,0 +1,77 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRowConvertsValues(t *testing.T) {
+	t.Parallel()
+	fields := []Field{countMetadata, ratioMetadata, enabledMetadata, nameMetadata, tagsMetadata}
+	raw := []interface{}{[]byte("0"), []byte("0.00000"), []byte("0"), []byte("john"), []byte(`["a","b"]`)}
+
+	row, err := NewRow(fields, raw)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"count", "ratio", "enabled", "name", "tags"}, row.Columns)
+	assert.Equal(t, int64(0), row.Get("count"))
+	assert.Equal(t, 0.0, row.Get("ratio"))
+	assert.Equal(t, false, row.Get("enabled"))
+	assert.Equal(t, "john", row.Get("name"))
+	assert.Equal(t, []interface{}{"a", "b"}, row.Get("tags"))
+}
+
+func TestNewRowKeepsNull(t *testing.T) {
+	t.Parallel()
+	row, err := NewRow([]Field{countMetadata, tagsMetadata}, []interface{}{nil, nil})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{nil, nil}, row.Values)
+}
+
+func TestNewRowUsesAlias(t *testing.T) {
+	t.Parallel()
+	field := Field{Name: "zygosity", Alias: "mother_zygosity", Type: StringType}
+	row, err := NewRow([]Field{field}, []interface{}{"HET"})
+	assert.NoError(t, err)
+	assert.Equal(t, "HET", row.Get("mother_zygosity"))
+	assert.Nil(t, row.Get("zygosity"))
+}
+
+func TestNewRowInvalidValue(t *testing.T) {
+	t.Parallel()
+	_, err := NewRow([]Field{countMetadata}, []interface{}{[]byte("abc")})
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "error converting value of count")
+}
+
+func TestNewRowConvertsDriverTypes(t *testing.T) {
+	t.Parallel()
+	date := Field{Name: "analysis_date", Type: DateType}
+	updated := Field{Name: "updated", Type: StringType}
+	fields := []Field{countMetadata, ratioMetadata, enabledMetadata, date, updated}
+	raw := []interface{}{int64(3), 0.25, true, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)}
+
+	row, err := NewRow(fields, raw)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{int64(3), 0.25, true, "2024-01-15", "2024-01-15T10:30:00Z"}, row.Values)
+}
+
+func TestNewRowUnsupportedDriverType(t *testing.T) {
+	t.Parallel()
+	_, err := NewRow([]Field{nameMetadata}, []interface{}{struct{}{}})
+	assert.ErrorContains(t, err, "unsupported value {} of type struct {}")
+}
+
+func TestRowMarshalJSONKeepsOrder(t *testing.T) {
+	t.Parallel()
+	row := Row{
+		Columns: []string{"zygosity", "af", "canonical", "gnomad_v3_af", "calls"},
+		Values:  []interface{}{"HET", 0.0, false, nil, []interface{}{json.Number("0"), json.Number("1")}},
+	}
+
+	data, err := json.Marshal(row)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"zygosity":"HET","af":0,"canonical":false,"gnomad_v3_af":null,"calls":[0,1]}`, string(data))
+}
@@ -0,0 +1,64 @@
+package types
+
+var SequencingExperimentTable = Table{
+	Name:  "sequencing_experiment",
+	Alias: "s",
+}
+
+var ExperimentSeqIdField = Field{
+	Name:          "seq_id",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	CanBeSorted:   true,
+	Table:         SequencingExperimentTable,
+	Type:          IntType,
+}
+var PatientIdField = Field{
+	Name:          "patient_id",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	CanBeSorted:   true,
+	CanBeSearched: true,
+	Table:         SequencingExperimentTable,
+	Type:          StringType,
+}
+var FamilyIdField = Field{
+	Name:          "family_id",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	CanBeSorted:   true,
+	CanBeSearched: true,
+	Table:         SequencingExperimentTable,
+	Type:          StringType,
+}
+var SampleTypeField = Field{
+	Name:          "sample_type",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         SequencingExperimentTable,
+	Type:          StringType,
+}
+var AnalysisDateField = Field{
+	Name:          "analysis_date",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	CanBeSorted:   true,
+	Table:         SequencingExperimentTable,
+	Type:          DateType,
+}
+var StatusField = Field{
+	Name:          "status",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         SequencingExperimentTable,
+	Type:          StringType,
+}
+
+var SequencingExperimentFields = []Field{
+	ExperimentSeqIdField,
+	PatientIdField,
+	FamilyIdField,
+	SampleTypeField,
+	AnalysisDateField,
+	StatusField,
+}
@@ -1,6 +1,8 @@
 package types
 
 import (
+	"fmt"
+
 	"github.com/Goldziher/go-utils/sliceutils"
 )
 
@@ -8,15 +10,33 @@ type Table struct {
 	Name  string // Name of the table
 	Alias string // Alias of the table to use in query
 }
+
+// FieldType is the data type of the values of a field
+type FieldType string
+
+const (
+	IntType     FieldType = "int"
+	DecimalType FieldType = "decimal"
+	StringType  FieldType = "string"
+	BoolType    FieldType = "bool"
+	EnumType    FieldType = "enum"
+	DateType    FieldType = "date" // Formatted as YYYY-MM-DD
+)
+
 type Field struct {
-	Name          string // Name of the field, correspond to column name
-	Alias         string // Alias of the field to use in query
-	CanBeSelected bool   // Whether the field is authorized for selection
-	CanBeFiltered bool   // Whether the field is authorized for filtering
-	CanBeSorted   bool   // Whether the field is authorized for sorting
-	CustomOp      string // Custom operation, e.g., "array_contains"
-	DefaultOp     string // Default operation to use if no custom one exists
-	Table         Table  // Table to which the field belongs
+	Name          string    // Name of the field, correspond to column name
+	Alias         string    // Alias of the field to use in query
+	CanBeSelected bool      // Whether the field is authorized for selection
+	CanBeFiltered bool      // Whether the field is authorized for filtering
+	CanBeSorted   bool      // Whether the field is authorized for sorting
+	CanBeSearched bool      // Whether the field is authorized for text search operations, e.g. "prefix"
+	CustomOp      string    // Custom operation, e.g., "array_contains"
+	DefaultOp     string    // Default operation to use if no custom one exists
+	Table         Table     // Table to which the field belongs
+	Type          FieldType // Type of the values of the field, values are not validated if empty
+	IsArray       bool      // Whether the field is an array of values of Type
+	EnumValues    *[]string // Allowed values when Type is EnumType
+	Expression    string    // SQL expression computing a derived field, used instead of the column when set
 }
 
 // GetAlias returns the alias of the field if it is set, otherwise returns the name
@@ -28,10 +48,22 @@ func (f *Field) GetAlias() string {
 	}
 }
 
-// FindByName returns the field with the given name from the list of fields
+// QualifiedName returns the name of the column prefixed by the alias of its table, if any, or the expression of a derived field
+func (f *Field) QualifiedName() string {
+	if f.Expression != "" {
+		return fmt.Sprintf("(%s)", f.Expression)
+	}
+	if f.Table.Alias != "" {
+		return fmt.Sprintf("%s.%s", f.Table.Alias, f.Name)
+	}
+	return f.Name
+}
+
+// FindByName returns the field with the given name from the list of fields. A field having an alias is only found by
+// its alias, so that the fields of different tables sharing a column name, e.g. zygosity and mother_zygosity, are told apart.
 func FindByName(fields *[]Field, name string) *Field {
 	return sliceutils.Find(*fields, func(field Field, index int, slice []Field) bool {
-		return field.Name == name
+		return field.GetAlias() == name
 	})
 
 }
@@ -62,3 +62,21 @@ func TestFindSortedFieldsWithBadOrder(t *testing.T) {
 	result := FindSortedFields(&fields, sorted)
 	assert.Equal(t, result, expected)
 }
+
+func TestFindByNameWithAlias(t *testing.T) {
+	t.Parallel()
+	fields := []Field{
+		{Name: "zygosity"},
+		{Name: "zygosity", Alias: "mother_zygosity"},
+	}
+	assert.Equal(t, FindByName(&fields, "zygosity"), &fields[0])
+	assert.Equal(t, FindByName(&fields, "mother_zygosity"), &fields[1])
+	assert.Equal(t, FindByName(&fields, "unknown") == nil, true)
+}
+
+func TestFindByNameIgnoresNameOfAliasedField(t *testing.T) {
+	t.Parallel()
+	fields := []Field{MotherZygosityField}
+	assert.Equal(t, FindByName(&fields, "zygosity") == nil, true)
+	assert.Equal(t, FindByName(&fields, "mother_zygosity"), &fields[0])
+}
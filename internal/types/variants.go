@@ -5,12 +5,38 @@ var VariantTable = Table{
 	Alias: "v",
 }
 
+var VepImpactValues = []string{"HIGH", "MODERATE", "LOW", "MODIFIER"}
+
+var VariantLocusIdField = Field{
+	Name:          "locus_id",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         VariantTable,
+	Type:          IntType,
+}
+var VariantChromosomeField = Field{
+	Name:          "chromosome",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	CanBeSorted:   true,
+	Table:         VariantTable,
+	Type:          StringType,
+}
+var VariantStartField = Field{
+	Name:          "start",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	CanBeSorted:   true,
+	Table:         VariantTable,
+	Type:          IntType,
+}
 var PfField = Field{
 	Name:          "pf",
 	CanBeSelected: true,
 	CanBeFiltered: true,
 	CanBeSorted:   true,
 	Table:         VariantTable,
+	Type:          DecimalType,
 }
 var AfField = Field{
 	Name:          "af",
@@ -18,6 +44,15 @@ var AfField = Field{
 	CanBeFiltered: true,
 	CanBeSorted:   true,
 	Table:         VariantTable,
+	Type:          DecimalType,
+}
+var GnomadV3AfField = Field{
+	Name:          "gnomad_v3_af",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	CanBeSorted:   true,
+	Table:         VariantTable,
+	Type:          DecimalType,
 }
 var VariantClassField = Field{
 	Name:          "variant_class",
@@ -25,11 +60,162 @@ var VariantClassField = Field{
 	CanBeFiltered: true,
 	CanBeSorted:   true,
 	Table:         VariantTable,
+	Type:          StringType,
 }
 var HgvsgField = Field{
 	Name:          "hgvsg",
 	CanBeSelected: true,
 	CanBeFiltered: true,
 	CanBeSorted:   true,
+	CanBeSearched: true,
+	Table:         VariantTable,
+	Type:          StringType,
+}
+var ClinvarInterpretationField = Field{
+	Name:          "clinvar_interpretation",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	CustomOp:      ArrayOperators,
+	Table:         VariantTable,
+	Type:          StringType,
+	IsArray:       true,
+}
+var ConsequenceField = Field{
+	Name:          "consequence",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	CustomOp:      ArrayOperators,
+	Table:         VariantTable,
+	Type:          StringType,
+	IsArray:       true,
+}
+var OmimInheritanceCodeField = Field{
+	Name:          "omim_inheritance_code",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	CustomOp:      ArrayOperators,
+	Table:         VariantTable,
+	Type:          StringType,
+	IsArray:       true,
+}
+var SymbolField = Field{
+	Name:          "symbol",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	CanBeSorted:   true,
+	CanBeSearched: true,
 	Table:         VariantTable,
+	Type:          StringType,
+}
+var DnaChangeField = Field{
+	Name:          "dna_change",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	CanBeSearched: true,
+	Table:         VariantTable,
+	Type:          StringType,
+}
+var LocusFullField = Field{
+	Name:          "locus_full",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	CanBeSearched: true,
+	Table:         VariantTable,
+	Type:          StringType,
+}
+var AcField = Field{
+	Name:          "ac",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	CanBeSorted:   true,
+	Table:         VariantTable,
+	Type:          IntType,
+}
+var PcField = Field{
+	Name:          "pc",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	CanBeSorted:   true,
+	Table:         VariantTable,
+	Type:          IntType,
+}
+var HomField = Field{
+	Name:          "hom",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	CanBeSorted:   true,
+	Table:         VariantTable,
+	Type:          IntType,
+}
+var VepImpactField = Field{
+	Name:          "vep_impact",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	CanBeSorted:   true,
+	Table:         VariantTable,
+	Type:          EnumType,
+	EnumValues:    &VepImpactValues,
+}
+var ManeSelectField = Field{
+	Name:          "mane_select",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         VariantTable,
+	Type:          BoolType,
+}
+var CanonicalField = Field{
+	Name:          "canonical",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         VariantTable,
+	Type:          BoolType,
+}
+var RsnumberField = Field{
+	Name:          "rsnumber",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	CustomOp:      ArrayOperators,
+	Table:         VariantTable,
+	Type:          StringType,
+	IsArray:       true,
+}
+var ReferenceField = Field{
+	Name:          "reference",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         VariantTable,
+	Type:          StringType,
+}
+var AlternateField = Field{
+	Name:          "alternate",
+	CanBeSelected: true,
+	CanBeFiltered: true,
+	Table:         VariantTable,
+	Type:          StringType,
+}
+
+var VariantsFields = []Field{
+	VariantLocusIdField,
+	VariantChromosomeField,
+	VariantStartField,
+	PfField,
+	AfField,
+	AcField,
+	PcField,
+	HomField,
+	GnomadV3AfField,
+	HgvsgField,
+	LocusFullField,
+	DnaChangeField,
+	ReferenceField,
+	AlternateField,
+	OmimInheritanceCodeField,
+	VariantClassField,
+	VepImpactField,
+	SymbolField,
+	ConsequenceField,
+	ClinvarInterpretationField,
+	RsnumberField,
+	ManeSelectField,
+	CanonicalField,
 }
@@ -0,0 +1,7 @@
+CREATE TABLE `cohort`
+(
+    `cohort_id`                       varchar(64) NOT NULL COMMENT "",
+    `seq_id`                          int     NOT NULL COMMENT ""
+
+) ENGINE = OLAP
+    PRIMARY KEY(`cohort_id`, `seq_id`);
@@ -0,0 +1,8 @@
+CREATE TABLE `family`
+(
+    `proband_seq_id`                  int     NOT NULL COMMENT "",
+    `mother_seq_id`                   int     NULL COMMENT "",
+    `father_seq_id`                   int     NULL COMMENT ""
+
+) ENGINE = OLAP
+    PRIMARY KEY(`proband_seq_id`);
@@ -1,7 +1,12 @@
 CREATE TABLE `sequencing_experiment`
 (
     `seq_id`                          int     NOT NULL COMMENT "",
-    `part`                            tinyint NOT NULL
+    `part`                            tinyint NOT NULL,
+    `patient_id`                      varchar(50) NULL COMMENT "",
+    `family_id`                       varchar(50) NULL COMMENT "",
+    `sample_type`                     varchar(50) NULL COMMENT "",
+    `analysis_date`                   date NULL COMMENT "",
+    `status`                          varchar(20) NULL COMMENT ""
 
 ) ENGINE = OLAP
-    PRIMARY KEY(`seq_id`);
\ No newline at end of file
+    PRIMARY KEY(`seq_id`);